@@ -0,0 +1,84 @@
+package events
+
+import (
+	"net/http"
+
+	"ddd/api/ctxutil"
+	"ddd/api/middleware"
+	"ddd/api/response"
+	eventsapp "ddd/application/events"
+	"ddd/config"
+	"ddd/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller 暴露进程内事件订阅的运维接口，挂载在 /admin 下并由管理令牌保护。
+type Controller struct {
+	subscriptionService *eventsapp.SubscriptionService
+	adminConfig         *config.AdminConfig
+}
+
+func NewController(subscriptionService *eventsapp.SubscriptionService, adminConfig *config.AdminConfig) *Controller {
+	return &Controller{
+		subscriptionService: subscriptionService,
+		adminConfig:         adminConfig,
+	}
+}
+
+func (c *Controller) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/admin/events/subscriptions", middleware.AdminAuthMiddleware(c.adminConfig))
+	group.GET("", c.ListSubscriptions)
+	group.POST("/:id/pause", c.PauseSubscription)
+	group.POST("/:id/resume", c.ResumeSubscription)
+}
+
+func (c *Controller) ListSubscriptions(ctx *gin.Context) {
+	var req eventsapp.ListSubscriptionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	resp := c.subscriptionService.ListSubscriptions(ctxutil.WithRequestID(ctx), req)
+	response.HandleSuccess(ctx, resp, "subscriptions retrieved successfully")
+}
+
+func (c *Controller) PauseSubscription(ctx *gin.Context) {
+	subscriptionID, ok := requiredPathParam(ctx, "id", "subscription ID is required")
+	if !ok {
+		return
+	}
+
+	resp, err := c.subscriptionService.PauseSubscription(ctxutil.WithRequestID(ctx), subscriptionID)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "subscription paused successfully")
+}
+
+func (c *Controller) ResumeSubscription(ctx *gin.Context) {
+	subscriptionID, ok := requiredPathParam(ctx, "id", "subscription ID is required")
+	if !ok {
+		return
+	}
+
+	resp, err := c.subscriptionService.ResumeSubscription(ctxutil.WithRequestID(ctx), subscriptionID)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "subscription resumed successfully")
+}
+
+func requiredPathParam(ctx *gin.Context, name, message string) (string, bool) {
+	value := ctx.Param(name)
+	if value == "" {
+		response.HandleError(ctx, errors.BadRequest(message), message, http.StatusBadRequest)
+		return "", false
+	}
+	return value, true
+}
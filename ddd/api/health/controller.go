@@ -1,12 +1,14 @@
 package health
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"runtime"
 	"time"
 
 	"ddd/config"
+	"ddd/infrastructure/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +17,12 @@ type Controller struct {
 	config    *config.Config
 	db        *sql.DB
 	startTime time.Time
+	outbox    OutboxReporter
+}
+
+// OutboxReporter 提供 outbox 积压与投递指标，由 metrics.OutboxMetrics 实现。
+type OutboxReporter interface {
+	Snapshot(ctx context.Context) (*metrics.OutboxSnapshot, error)
 }
 
 func NewController(cfg *config.Config, db interface{}) *Controller {
@@ -30,6 +38,13 @@ func NewController(cfg *config.Config, db interface{}) *Controller {
 		startTime: time.Now(),
 	}
 }
+
+// WithOutbox 使 /health 同时展示 outbox 指标。
+func (c *Controller) WithOutbox(reporter OutboxReporter) *Controller {
+	c.outbox = reporter
+	return c
+}
+
 func (c *Controller) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("/health", c.Health)
 	router.GET("/health/live", c.Liveness)
@@ -37,12 +52,13 @@ func (c *Controller) RegisterRoutes(router *gin.RouterGroup) {
 }
 
 type HealthResponse struct {
-	Status    string           `json:"status"`
-	Version   string           `json:"version"`
-	Uptime    string           `json:"uptime"`
-	Timestamp string           `json:"timestamp"`
-	Checks    map[string]Check `json:"checks,omitempty"`
-	System    *SystemInfo      `json:"system,omitempty"`
+	Status    string                  `json:"status"`
+	Version   string                  `json:"version"`
+	Uptime    string                  `json:"uptime"`
+	Timestamp string                  `json:"timestamp"`
+	Checks    map[string]Check        `json:"checks,omitempty"`
+	Outbox    *metrics.OutboxSnapshot `json:"outbox,omitempty"`
+	System    *SystemInfo             `json:"system,omitempty"`
 }
 type Check struct {
 	Status  string `json:"status"`
@@ -74,6 +90,10 @@ func (c *Controller) Health(ctx *gin.Context) {
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Checks:    checks,
 	}
+	if c.outbox != nil {
+		// outbox 积压只反映下游投递情况，不影响实例的健康状态。
+		response.Outbox, checks["outbox"] = c.checkOutbox(ctx.Request.Context())
+	}
 	if c.config.IsDevelopment() {
 		var memStats runtime.MemStats
 		runtime.ReadMemStats(&memStats)
@@ -137,3 +157,20 @@ func (c *Controller) checkDatabase() Check {
 		Latency: latency.String(),
 	}
 }
+func (c *Controller) checkOutbox(ctx context.Context) (*metrics.OutboxSnapshot, Check) {
+	start := time.Now()
+	snapshot, err := c.outbox.Snapshot(ctx)
+	latency := time.Since(start)
+
+	if err != nil {
+		return nil, Check{
+			Status:  "unhealthy",
+			Message: err.Error(),
+			Latency: latency.String(),
+		}
+	}
+	return snapshot, Check{
+		Status:  "healthy",
+		Latency: latency.String(),
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strconv"
 	"sync"
@@ -249,3 +250,45 @@ func RateLimitMiddleware(cfg *config.RateLimitConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+const (
+	AdminTokenHeader = "X-Admin-Token"
+)
+
+// AdminAuthMiddleware 校验管理接口令牌；未配置令牌时拒绝所有请求。
+func AdminAuthMiddleware(cfg *config.AdminConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, _ := c.Get(response.RequestIDKey)
+		reqID, _ := requestID.(string)
+
+		if cfg == nil || cfg.Token == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, response.Response{
+				Success:   false,
+				Error:     "admin_disabled",
+				Message:   "admin token is not configured",
+				Code:      http.StatusServiceUnavailable,
+				RequestID: reqID,
+			})
+			return
+		}
+
+		token := c.GetHeader(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+			logger.Warn("Admin authentication failed",
+				zap.String("request_id", reqID),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("client_ip", c.ClientIP()))
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Response{
+				Success:   false,
+				Error:     "unauthorized",
+				Message:   "invalid admin token",
+				Code:      http.StatusUnauthorized,
+				RequestID: reqID,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
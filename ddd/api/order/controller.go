@@ -2,6 +2,7 @@ package order
 
 import (
 	"net/http"
+	"time"
 
 	"ddd/api/ctxutil"
 	"ddd/api/response"
@@ -23,6 +24,7 @@ func (c *Controller) RegisterRoutes(router *gin.RouterGroup) {
 	orderGroup := router.Group("/orders")
 	orderGroup.POST("", c.CreateOrder)
 	orderGroup.GET("/:id", c.GetOrder)
+	orderGroup.GET("/:id/as-of", c.GetOrderAsOf)
 	orderGroup.GET("/user/:userId", c.GetUserOrders)
 	orderGroup.PUT("/:id/status", c.UpdateOrderStatus)
 	orderGroup.POST("/:id/process", c.ProcessOrder)
@@ -59,6 +61,29 @@ func (c *Controller) GetOrder(ctx *gin.Context) {
 	response.HandleSuccess(ctx, resp, "order retrieved successfully")
 }
 
+// GetOrderAsOf 返回订单在 at（RFC 3339，如 2026-10-15T10:00:00+08:00）时刻的状态。
+func (c *Controller) GetOrderAsOf(ctx *gin.Context) {
+	orderID, ok := requiredPathParam(ctx, "id", "order ID is required")
+	if !ok {
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, ctx.Query("at"))
+	if err != nil {
+		message := "query parameter at must be an RFC 3339 timestamp"
+		response.HandleError(ctx, errors.BadRequest(message), message, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.orderService.GetOrderAsOf(ctxutil.WithRequestID(ctx), orderID, at)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "order history retrieved successfully")
+}
+
 func (c *Controller) GetUserOrders(ctx *gin.Context) {
 	userID, ok := requiredPathParam(ctx, "userId", "user ID is required")
 	if !ok {
@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"net/http"
+
+	"ddd/api/ctxutil"
+	"ddd/api/middleware"
+	"ddd/api/response"
+	outboxapp "ddd/application/outbox"
+	"ddd/config"
+	"ddd/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller 暴露 outbox 死信的运维管理接口，挂载在 /admin 下并由管理令牌保护。
+type Controller struct {
+	deadLetterService *outboxapp.DeadLetterService
+	adminConfig       *config.AdminConfig
+}
+
+func NewController(deadLetterService *outboxapp.DeadLetterService, adminConfig *config.AdminConfig) *Controller {
+	return &Controller{
+		deadLetterService: deadLetterService,
+		adminConfig:       adminConfig,
+	}
+}
+
+func (c *Controller) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/admin/outbox/dead-letters", middleware.AdminAuthMiddleware(c.adminConfig))
+	group.GET("", c.ListDeadLetters)
+	group.GET("/:id", c.GetDeadLetter)
+	group.POST("/replay", c.ReplayDeadLetters)
+	group.POST("/:id/replay", c.ReplayDeadLetter)
+	group.POST("/discard", c.DiscardDeadLetters)
+	group.POST("/:id/discard", c.DiscardDeadLetter)
+}
+
+func (c *Controller) ListDeadLetters(ctx *gin.Context) {
+	var req outboxapp.ListDeadLettersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.deadLetterService.ListDeadLetters(ctxutil.WithRequestID(ctx), req)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	totalPages := int((resp.Total + int64(resp.PageSize) - 1) / int64(resp.PageSize))
+	response.HandlePaginated(ctx, resp.Items, response.Pagination{
+		Page:       resp.Page,
+		PageSize:   resp.PageSize,
+		TotalItems: resp.Total,
+		TotalPages: totalPages,
+	}, "dead letters retrieved successfully")
+}
+
+func (c *Controller) GetDeadLetter(ctx *gin.Context) {
+	eventID, ok := requiredPathParam(ctx, "id", "event ID is required")
+	if !ok {
+		return
+	}
+
+	resp, err := c.deadLetterService.GetDeadLetter(ctxutil.WithRequestID(ctx), eventID)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "dead letter retrieved successfully")
+}
+
+func (c *Controller) ReplayDeadLetters(ctx *gin.Context) {
+	var req outboxapp.ReplayDeadLettersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return
+	}
+	c.replay(ctx, req)
+}
+
+type SingleActionRequest struct {
+	Operator string `json:"operator"`
+	Note     string `json:"note"`
+}
+
+func (c *Controller) ReplayDeadLetter(ctx *gin.Context) {
+	eventID, ok := requiredPathParam(ctx, "id", "event ID is required")
+	if !ok {
+		return
+	}
+
+	var req SingleActionRequest
+	if !bindOptionalJSON(ctx, &req) {
+		return
+	}
+	c.replay(ctx, outboxapp.ReplayDeadLettersRequest{
+		IDs:      []string{eventID},
+		Operator: req.Operator,
+		Note:     req.Note,
+	})
+}
+
+func (c *Controller) replay(ctx *gin.Context, req outboxapp.ReplayDeadLettersRequest) {
+	resp, err := c.deadLetterService.ReplayDeadLetters(ctxutil.WithRequestID(ctx), req)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "dead letters replayed successfully")
+}
+
+func (c *Controller) DiscardDeadLetters(ctx *gin.Context) {
+	var req outboxapp.DiscardDeadLettersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return
+	}
+	c.discard(ctx, req)
+}
+
+func (c *Controller) DiscardDeadLetter(ctx *gin.Context) {
+	eventID, ok := requiredPathParam(ctx, "id", "event ID is required")
+	if !ok {
+		return
+	}
+
+	var req SingleActionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return
+	}
+	c.discard(ctx, outboxapp.DiscardDeadLettersRequest{
+		IDs:      []string{eventID},
+		Operator: req.Operator,
+		Note:     req.Note,
+	})
+}
+
+func (c *Controller) discard(ctx *gin.Context, req outboxapp.DiscardDeadLettersRequest) {
+	resp, err := c.deadLetterService.DiscardDeadLetters(ctxutil.WithRequestID(ctx), req)
+	if err != nil {
+		response.HandleAppError(ctx, err)
+		return
+	}
+
+	response.HandleSuccess(ctx, resp, "dead letters discarded successfully")
+}
+
+func bindOptionalJSON(ctx *gin.Context, req interface{}) bool {
+	if ctx.Request.ContentLength == 0 {
+		return true
+	}
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		response.HandleError(ctx, err, "invalid request parameters", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func requiredPathParam(ctx *gin.Context, name, message string) (string, bool) {
+	value := ctx.Param(name)
+	if value == "" {
+		response.HandleError(ctx, errors.BadRequest(message), message, http.StatusBadRequest)
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,28 @@
+package events
+
+import "time"
+
+// ListSubscriptionsRequest 表示进程内事件订阅列表的查询入参。
+type ListSubscriptionsRequest struct {
+	EventName  string `form:"event_name"`
+	ActiveOnly bool   `form:"active_only"`
+}
+
+// SubscriptionResponse 表示订阅及其最近处理结果。
+type SubscriptionResponse struct {
+	ID        string                   `json:"id"`
+	EventName string                   `json:"event_name"`
+	Handler   string                   `json:"handler"`
+	Filtered  bool                     `json:"filtered"`
+	IsActive  bool                     `json:"is_active"`
+	CreatedAt time.Time                `json:"created_at"`
+	History   []*HandlerResultResponse `json:"history"`
+}
+
+// HandlerResultResponse 表示一次事件处理的结果。
+type HandlerResultResponse struct {
+	EventName   string    `json:"event_name"`
+	Success     bool      `json:"success"`
+	Message     string    `json:"message,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
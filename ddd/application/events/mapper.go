@@ -0,0 +1,24 @@
+package events
+
+import "ddd/domain/shared"
+
+func toSubscriptionResponse(status shared.SubscriptionStatus) *SubscriptionResponse {
+	history := make([]*HandlerResultResponse, len(status.History))
+	for i, result := range status.History {
+		history[i] = &HandlerResultResponse{
+			EventName:   result.EventName,
+			Success:     result.Success,
+			Message:     result.Message,
+			PublishedAt: result.PublishedAt,
+		}
+	}
+	return &SubscriptionResponse{
+		ID:        status.ID,
+		EventName: status.EventName,
+		Handler:   status.HandlerName,
+		Filtered:  status.Filtered,
+		IsActive:  status.IsActive,
+		CreatedAt: status.CreatedAt,
+		History:   history,
+	}
+}
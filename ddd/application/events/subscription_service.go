@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"ddd/domain/shared"
+)
+
+// SubscriptionRegistry 由 shared.EventBus 实现。
+type SubscriptionRegistry interface {
+	Subscriptions() []shared.SubscriptionStatus
+	SetSubscriptionActive(subscriptionID string, active bool) error
+}
+
+// SubscriptionService 编排进程内事件订阅的查询、暂停与恢复。
+type SubscriptionService struct {
+	registry SubscriptionRegistry
+}
+
+func NewSubscriptionService(registry SubscriptionRegistry) *SubscriptionService {
+	return &SubscriptionService{registry: registry}
+}
+
+// ListSubscriptions 按订阅顺序返回订阅，EventName 为订阅时使用的事件名或模式。
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, req ListSubscriptionsRequest) []*SubscriptionResponse {
+	items := make([]*SubscriptionResponse, 0)
+	for _, status := range s.registry.Subscriptions() {
+		if req.EventName != "" && status.EventName != req.EventName {
+			continue
+		}
+		if req.ActiveOnly && !status.IsActive {
+			continue
+		}
+		items = append(items, toSubscriptionResponse(status))
+	}
+	return items
+}
+
+func (s *SubscriptionService) PauseSubscription(ctx context.Context, subscriptionID string) (*SubscriptionResponse, error) {
+	return s.setActive(subscriptionID, false)
+}
+
+func (s *SubscriptionService) ResumeSubscription(ctx context.Context, subscriptionID string) (*SubscriptionResponse, error) {
+	return s.setActive(subscriptionID, true)
+}
+
+func (s *SubscriptionService) setActive(subscriptionID string, active bool) (*SubscriptionResponse, error) {
+	if err := s.registry.SetSubscriptionActive(subscriptionID, active); err != nil {
+		return nil, err
+	}
+	for _, status := range s.registry.Subscriptions() {
+		if status.ID == subscriptionID {
+			return toSubscriptionResponse(status), nil
+		}
+	}
+	// 并发的 Unsubscribe 可能在两次调用之间移除了订阅。
+	return nil, fmt.Errorf("subscription %s: %w", subscriptionID, shared.ErrNotFound)
+}
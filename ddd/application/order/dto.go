@@ -35,6 +35,12 @@ type OrderResponse struct {
 	UpdatedAt   time.Time           `json:"updated_at"`
 }
 
+// OrderAsOfResponse 表示订单在某一时刻的状态，UpdatedAt 为该时刻之前最后一次变更的时间。
+type OrderAsOfResponse struct {
+	OrderResponse
+	AsOf time.Time `json:"as_of"`
+}
+
 // OrderItemResponse 表示订单项返回模型。
 type OrderItemResponse struct {
 	ProductID   string        `json:"product_id"`
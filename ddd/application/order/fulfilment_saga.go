@@ -0,0 +1,194 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+)
+
+// OrderFulfilmentSagaType 是订单履约 saga 的类型名。
+const OrderFulfilmentSagaType = "order_fulfilment"
+
+// StockItem 是库存预留中的一行。
+type StockItem struct {
+	ProductID string
+	Quantity  int
+}
+
+// InventoryService 是库存上下文的端口。实现以订单 ID 作为幂等键，重复预留返回同一预留 ID；
+// 库存不足等业务拒绝应以 shared.RejectSagaStep 包装，避免无意义的重试。
+type InventoryService interface {
+	Reserve(ctx context.Context, orderID string, items []StockItem) (string, error)
+	Release(ctx context.Context, reservationID string) error
+}
+
+// PaymentService 是支付上下文的端口。实现以订单 ID 作为幂等键，重复扣款返回同一支付 ID；
+// 支付被拒应以 shared.RejectSagaStep 包装。
+type PaymentService interface {
+	Charge(ctx context.Context, orderID, userID string, amount shared.Money) (string, error)
+	Refund(ctx context.Context, paymentID string) error
+}
+
+type FulfilmentSagaConfig struct {
+	// StepTimeout 为每个步骤单次执行的超时。
+	StepTimeout time.Duration
+	// MaxAttempts 为每个步骤的最大尝试次数。
+	MaxAttempts int
+}
+
+// FulfilmentSaga 编排下单之后的履约流程：校验订单 → 预留库存 → 扣款 → 确认订单。
+// 任一步骤失败时逆序退款、释放库存并取消订单；流程中订单被取消时同样补偿已完成的步骤。
+type FulfilmentSaga struct {
+	orders    *ApplicationService
+	inventory InventoryService
+	payments  PaymentService
+	config    FulfilmentSagaConfig
+}
+
+func NewFulfilmentSaga(orders *ApplicationService, inventory InventoryService, payments PaymentService, config FulfilmentSagaConfig) *FulfilmentSaga {
+	return &FulfilmentSaga{
+		orders:    orders,
+		inventory: inventory,
+		payments:  payments,
+		config:    config,
+	}
+}
+
+// Definition 返回交给 saga 管理器注册的定义。
+func (s *FulfilmentSaga) Definition() shared.SagaDefinition {
+	return shared.SagaDefinition{
+		Type:    OrderFulfilmentSagaType,
+		StartOn: "order.placed",
+		Start: func(event shared.DomainEvent) (map[string]string, bool) {
+			placed, ok := event.(*order.OrderPlacedEvent)
+			if !ok {
+				return nil, false
+			}
+			return map[string]string{"user_id": placed.UserID()}, true
+		},
+		AbortOn: []string{"order.cancelled"},
+		Steps: []shared.SagaStep{
+			s.step("place_order", s.checkOrder, s.cancelOrder),
+			s.step("reserve_stock", s.reserveStock, s.releaseStock),
+			s.step("take_payment", s.takePayment, s.refundPayment),
+			s.step("confirm_order", s.confirmOrder, nil),
+		},
+	}
+}
+
+func (s *FulfilmentSaga) step(name string, action, compensate func(context.Context, *shared.SagaState) error) shared.SagaStep {
+	return shared.SagaStep{
+		Name:        name,
+		Action:      action,
+		Compensate:  compensate,
+		Timeout:     s.config.StepTimeout,
+		MaxAttempts: s.config.MaxAttempts,
+	}
+}
+
+// checkOrder 确认订单仍待处理；订单已被取消或已由人工推进时不再履约。
+func (s *FulfilmentSaga) checkOrder(ctx context.Context, state *shared.SagaState) error {
+	o, err := s.orders.orderRepo.FindByID(ctx, state.CorrelationID)
+	if err != nil {
+		return rejectOrderError(err)
+	}
+	if o.Status() != order.StatusPending {
+		return shared.RejectSagaStep(order.NewInvalidOrderStateError(string(o.Status()), string(order.StatusConfirmed)))
+	}
+	return nil
+}
+
+// cancelOrder 以补偿原因取消订单，订单已取消时视为成功。
+func (s *FulfilmentSaga) cancelOrder(ctx context.Context, state *shared.SagaState) error {
+	uow := s.orders.uowFactory.New()
+	return uow.Execute(ctx, func(ctx context.Context) error {
+		o, err := s.orders.orderRepo.FindByID(ctx, state.CorrelationID)
+		if err != nil {
+			return err
+		}
+		if o.Status() == order.StatusCancelled {
+			return nil
+		}
+		if err := o.Cancel(state.Reason); err != nil {
+			return err
+		}
+		if err := s.orders.orderRepo.Save(ctx, o); err != nil {
+			return err
+		}
+		uow.RegisterDirty(o)
+		return nil
+	})
+}
+
+func (s *FulfilmentSaga) reserveStock(ctx context.Context, state *shared.SagaState) error {
+	o, err := s.orders.orderRepo.FindByID(ctx, state.CorrelationID)
+	if err != nil {
+		return rejectOrderError(err)
+	}
+	items := make([]StockItem, len(o.Items()))
+	for i, item := range o.Items() {
+		items[i] = StockItem{ProductID: item.ProductID(), Quantity: item.Quantity()}
+	}
+	reservationID, err := s.inventory.Reserve(ctx, o.ID(), items)
+	if err != nil {
+		return err
+	}
+	state.Data["reservation_id"] = reservationID
+	return nil
+}
+
+func (s *FulfilmentSaga) releaseStock(ctx context.Context, state *shared.SagaState) error {
+	if state.Data["reservation_id"] == "" {
+		return nil
+	}
+	return s.inventory.Release(ctx, state.Data["reservation_id"])
+}
+
+func (s *FulfilmentSaga) takePayment(ctx context.Context, state *shared.SagaState) error {
+	o, err := s.orders.orderRepo.FindByID(ctx, state.CorrelationID)
+	if err != nil {
+		return rejectOrderError(err)
+	}
+	paymentID, err := s.payments.Charge(ctx, o.ID(), o.UserID(), o.TotalAmount())
+	if err != nil {
+		return err
+	}
+	state.Data["payment_id"] = paymentID
+	return nil
+}
+
+func (s *FulfilmentSaga) refundPayment(ctx context.Context, state *shared.SagaState) error {
+	if state.Data["payment_id"] == "" {
+		return nil
+	}
+	return s.payments.Refund(ctx, state.Data["payment_id"])
+}
+
+// confirmOrder 复用 ProcessOrder 的校验。重试时上一次的确认可能已经提交，订单已确认即视为成功。
+func (s *FulfilmentSaga) confirmOrder(ctx context.Context, state *shared.SagaState) error {
+	err := s.orders.ProcessOrder(ctx, state.CorrelationID)
+	if errors.Is(err, order.ErrInvalidOrderStateTransition) {
+		if o, findErr := s.orders.orderRepo.FindByID(ctx, state.CorrelationID); findErr == nil && o.Status() == order.StatusConfirmed {
+			return nil
+		}
+	}
+	return rejectOrderError(err)
+}
+
+// rejectOrderError 将订单不存在、状态不允许等确定性错误标记为业务拒绝。
+func rejectOrderError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, order.ErrOrderNotFound),
+		errors.Is(err, order.ErrInvalidOrderStateTransition),
+		errors.Is(err, order.ErrInvalidOrderState),
+		errors.Is(err, order.ErrUserNotActiveForOrder):
+		return shared.RejectSagaStep(err)
+	default:
+		return err
+	}
+}
@@ -3,6 +3,7 @@ package order
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"ddd/domain/order"
 	"ddd/domain/shared"
@@ -15,6 +16,7 @@ type ApplicationService struct {
 	orderDomainService *order.DomainService
 	userDomainService  *user.DomainService
 	uowFactory         shared.UnitOfWorkFactory
+	historyReader      order.HistoryReader
 }
 
 func NewApplicationService(
@@ -31,6 +33,11 @@ func NewApplicationService(
 	}
 }
 
+// SetHistoryReader 设置订单历史查询，未设置时 GetOrderAsOf 不可用。
+func (s *ApplicationService) SetHistoryReader(reader order.HistoryReader) {
+	s.historyReader = reader
+}
+
 func (s *ApplicationService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*OrderResponse, error) {
 	var o *order.Order
 	uow := s.uowFactory.New()
@@ -71,6 +78,18 @@ func (s *ApplicationService) GetOrder(ctx context.Context, orderID string) (*Ord
 	return toOrderResponse(o), nil
 }
 
+// GetOrderAsOf 返回订单在 at 时刻的状态、订单项与金额。
+func (s *ApplicationService) GetOrderAsOf(ctx context.Context, orderID string, at time.Time) (*OrderAsOfResponse, error) {
+	if s.historyReader == nil {
+		return nil, fmt.Errorf("order history is not available for the configured order store")
+	}
+	o, err := s.historyReader.FindAsOf(ctx, orderID, at)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderAsOfResponse{OrderResponse: *toOrderResponse(o), AsOf: at}, nil
+}
+
 func (s *ApplicationService) GetUserOrders(ctx context.Context, userID string) ([]*OrderResponse, error) {
 	orders, err := s.orderRepo.FindByUserID(ctx, userID)
 	if err != nil {
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+
+	"ddd/domain/shared"
+)
+
+const (
+	defaultPageSize    = 50
+	defaultReplayLimit = 100
+	defaultOperator    = "unknown"
+)
+
+// DeadLetterService 编排死信（FAILED outbox 事件）的查询、重放与丢弃。
+type DeadLetterService struct {
+	repo shared.DeadLetterRepository
+}
+
+func NewDeadLetterService(repo shared.DeadLetterRepository) *DeadLetterService {
+	return &DeadLetterService{repo: repo}
+}
+
+func (s *DeadLetterService) ListDeadLetters(ctx context.Context, req ListDeadLettersRequest) (*DeadLetterListResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	events, total, err := s.repo.ListFailedEvents(ctx, shared.DeadLetterFilter{
+		EventType:   req.EventType,
+		AggregateID: req.AggregateID,
+		Limit:       pageSize,
+		Offset:      (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*DeadLetterResponse, len(events))
+	for i, event := range events {
+		items[i] = toDeadLetterResponse(event, false)
+	}
+	return &DeadLetterListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+func (s *DeadLetterService) GetDeadLetter(ctx context.Context, eventID string) (*DeadLetterResponse, error) {
+	event, err := s.repo.GetFailedEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return toDeadLetterResponse(event, true), nil
+}
+
+// ReplayDeadLetters 指定 IDs 时精确重放，否则按过滤条件重放最早的 Limit 条死信。
+func (s *DeadLetterService) ReplayDeadLetters(ctx context.Context, req ReplayDeadLettersRequest) (*DeadLetterActionResponse, error) {
+	eventIDs := req.IDs
+	if len(eventIDs) == 0 {
+		if req.EventType == "" && req.AggregateID == "" {
+			return nil, shared.NewValidationError("dead letter", "ids", "ids or a filter (event_type/aggregate_id) is required")
+		}
+		limit := req.Limit
+		if limit <= 0 {
+			limit = defaultReplayLimit
+		}
+		events, _, err := s.repo.ListFailedEvents(ctx, shared.DeadLetterFilter{
+			EventType:   req.EventType,
+			AggregateID: req.AggregateID,
+			Limit:       limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		eventIDs = make([]string, len(events))
+		for i, event := range events {
+			eventIDs[i] = event.ID
+		}
+	}
+
+	affected, err := s.repo.ReplayFailedEvents(ctx, eventIDs, toAudit(req.Operator, req.Note))
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetterActionResponse{Requested: len(eventIDs), Affected: affected}, nil
+}
+
+func (s *DeadLetterService) DiscardDeadLetters(ctx context.Context, req DiscardDeadLettersRequest) (*DeadLetterActionResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, shared.NewValidationError("dead letter", "ids", "at least one event ID is required")
+	}
+	if req.Note == "" {
+		return nil, shared.NewValidationError("dead letter", "note", "an audit note is required to discard events")
+	}
+
+	affected, err := s.repo.DiscardFailedEvents(ctx, req.IDs, toAudit(req.Operator, req.Note))
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetterActionResponse{Requested: len(req.IDs), Affected: affected}, nil
+}
+
+func toAudit(operator, note string) shared.DeadLetterAudit {
+	if operator == "" {
+		operator = defaultOperator
+	}
+	return shared.DeadLetterAudit{Operator: operator, Note: note}
+}
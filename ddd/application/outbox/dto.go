@@ -0,0 +1,56 @@
+package outbox
+
+import "time"
+
+// ListDeadLettersRequest 表示死信列表查询入参。
+type ListDeadLettersRequest struct {
+	EventType   string `form:"event_type"`
+	AggregateID string `form:"aggregate_id"`
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1,max=500"`
+}
+
+// ReplayDeadLettersRequest 表示重放死信入参；IDs 为空时按过滤条件批量重放。
+type ReplayDeadLettersRequest struct {
+	IDs         []string `json:"ids"`
+	EventType   string   `json:"event_type"`
+	AggregateID string   `json:"aggregate_id"`
+	Limit       int      `json:"limit" binding:"omitempty,min=1,max=1000"`
+	Operator    string   `json:"operator"`
+	Note        string   `json:"note"`
+}
+
+// DiscardDeadLettersRequest 表示丢弃死信入参，丢弃必须附带审计说明。
+type DiscardDeadLettersRequest struct {
+	IDs      []string `json:"ids" binding:"required,min=1"`
+	Operator string   `json:"operator"`
+	Note     string   `json:"note" binding:"required"`
+}
+
+// DeadLetterResponse 表示死信返回模型。
+type DeadLetterResponse struct {
+	ID                string    `json:"id"`
+	AggregateID       string    `json:"aggregate_id"`
+	AggregateSequence int64     `json:"aggregate_sequence"`
+	EventType         string    `json:"event_type"`
+	EventVersion      int       `json:"event_version"`
+	Payload           string    `json:"payload,omitempty"`
+	RetryCount        int       `json:"retry_count"`
+	LastError         string    `json:"last_error,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DeadLetterListResponse 表示死信分页结果。
+type DeadLetterListResponse struct {
+	Items    []*DeadLetterResponse `json:"items"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// DeadLetterActionResponse 表示重放/丢弃的处理结果。
+type DeadLetterActionResponse struct {
+	Requested int   `json:"requested"`
+	Affected  int64 `json:"affected"`
+}
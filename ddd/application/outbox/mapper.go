@@ -0,0 +1,21 @@
+package outbox
+
+import "ddd/domain/shared"
+
+func toDeadLetterResponse(event *shared.DeadLetterEvent, withPayload bool) *DeadLetterResponse {
+	resp := &DeadLetterResponse{
+		ID:                event.ID,
+		AggregateID:       event.AggregateID,
+		AggregateSequence: event.AggregateSequence,
+		EventType:         event.EventType,
+		EventVersion:      event.EventVersion,
+		RetryCount:        event.RetryCount,
+		LastError:         event.LastError,
+		CreatedAt:         event.CreatedAt,
+		UpdatedAt:         event.UpdatedAt,
+	}
+	if withPayload {
+		resp.Payload = event.Payload
+	}
+	return resp
+}
@@ -15,6 +15,7 @@ type ApplicationService struct {
 	orderRepo         order.Repository
 	userDomainService *user.DomainService
 	uowFactory        shared.UnitOfWorkFactory
+	spendingReader    order.UserSpendingReader
 }
 
 func NewApplicationService(
@@ -30,6 +31,11 @@ func NewApplicationService(
 	}
 }
 
+// SetSpendingReader 设置用户消费汇总读模型，设置后 GetUserTotalSpent 不再加载订单求和。
+func (s *ApplicationService) SetSpendingReader(reader order.UserSpendingReader) {
+	s.spendingReader = reader
+}
+
 type CreateUserRequest struct {
 	Name  string `json:"name" binding:"required"`
 	Email string `json:"email" binding:"required,email"`
@@ -115,6 +121,10 @@ type GetUserTotalSpentResponse struct {
 }
 
 func (s *ApplicationService) GetUserTotalSpent(ctx context.Context, req GetUserTotalSpentRequest) (*GetUserTotalSpentResponse, error) {
+	if s.spendingReader != nil {
+		return s.getUserTotalSpentFromReadModel(ctx, req)
+	}
+
 	orders, err := s.orderRepo.FindDeliveredOrdersByUserID(ctx, req.UserID)
 	if err != nil {
 		return nil, err
@@ -138,6 +148,35 @@ func (s *ApplicationService) GetUserTotalSpent(ctx context.Context, req GetUserT
 		Currency:    total.Currency(),
 	}, nil
 }
+
+// getUserTotalSpentFromReadModel 从投影读取已签收订单总额，结果可能滞后于最近的签收。
+func (s *ApplicationService) getUserTotalSpentFromReadModel(ctx context.Context, req GetUserTotalSpentRequest) (*GetUserTotalSpentResponse, error) {
+	summaries, err := s.spendingReader.FindUserSpending(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := shared.NewMoney(0, "CNY")
+	for _, summary := range summaries {
+		if summary.TotalSpent == 0 {
+			continue
+		}
+		if summary.Currency != total.Currency() {
+			return nil, fmt.Errorf("mixed currencies not supported: %s vs %s", summary.Currency, total.Currency())
+		}
+		total, err = total.Add(*shared.NewMoney(summary.TotalSpent, summary.Currency))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &GetUserTotalSpentResponse{
+		UserID:      req.UserID,
+		TotalAmount: total.Amount(),
+		Currency:    total.Currency(),
+	}, nil
+}
+
 func (s *ApplicationService) convertToResponse(u *user.User) *UserResponse {
 	return &UserResponse{
 		ID:        u.ID(),
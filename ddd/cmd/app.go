@@ -10,6 +10,8 @@ import (
 
 	"ddd/api"
 	"ddd/config"
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence/mysql"
 	"ddd/pkg/logger"
 
 	"go.uber.org/zap"
@@ -22,6 +24,13 @@ type App struct {
 	router *api.Router
 	server *http.Server
 	db     *gorm.DB
+
+	eventBus *shared.EventBus
+
+	outbox        *OutboxRuntime
+	outboxElector *mysql.LeaderElector
+	stopOutbox    context.CancelFunc
+	outboxDone    chan struct{}
 }
 
 // NewApp 为兼容旧调用保留，内部统一走 Builder。
@@ -32,15 +41,20 @@ func NewApp(cfg *config.Config) *App {
 // Run 启动服务并在收到退出信号后优雅关闭。
 func (a *App) Run() error {
 	a.startHTTPServer()
+	a.startOutboxWorker()
 	a.waitForShutdownSignal()
 
 	logger.Info("Shutting down server...")
 
 	if err := a.shutdownHTTPServer(); err != nil {
 		logger.Error("Server forced to shutdown", zap.Error(err))
+		a.closeEventBus()
+		a.stopOutboxWorker()
 		return err
 	}
 
+	a.closeEventBus()
+	a.stopOutboxWorker()
 	a.closeDatabase()
 
 	logger.Info("Server exited properly")
@@ -59,6 +73,48 @@ func (a *App) startHTTPServer() {
 	}()
 }
 
+// startOutboxWorker 在启用内嵌 worker 时参与选主，仅当选的副本轮询 outbox。
+func (a *App) startOutboxWorker() {
+	if a.outbox == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopOutbox = cancel
+	a.outboxDone = make(chan struct{})
+	go func() {
+		defer close(a.outboxDone)
+		err := a.outboxElector.Run(ctx, a.outbox.Run)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Embedded outbox worker exited with error", zap.Error(err))
+		}
+	}()
+	logger.Info("Embedded outbox worker waiting for leadership",
+		zap.String("lock", a.config.Worker.LeaderElection.LockName))
+}
+
+// stopOutboxWorker 停止 worker 并等待在途投递完成、释放选主锁，需在关闭数据库之前调用。
+func (a *App) stopOutboxWorker() {
+	if a.outbox == nil || a.stopOutbox == nil {
+		return
+	}
+	a.stopOutbox()
+	<-a.outboxDone
+	a.outbox.Close()
+}
+
+// closeEventBus 等待进程内事件处理完毕，处理器可能访问数据库，需在关闭数据库之前调用。
+func (a *App) closeEventBus() {
+	if a.eventBus == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Events.DrainTimeout)
+	defer cancel()
+	if err := a.eventBus.Close(ctx); err != nil {
+		logger.Warn("Event bus did not drain before shutdown", zap.Error(err))
+	}
+}
+
 func (a *App) waitForShutdownSignal() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -6,15 +6,21 @@ import (
 	"os"
 
 	"ddd/api"
+	apievents "ddd/api/events"
 	"ddd/api/health"
 	apiorder "ddd/api/order"
+	apioutbox "ddd/api/outbox"
 	apiuser "ddd/api/user"
+	eventsapp "ddd/application/events"
 	orderapp "ddd/application/order"
+	outboxapp "ddd/application/outbox"
 	userapp "ddd/application/user"
 	"ddd/config"
 	orderdomain "ddd/domain/order"
 	"ddd/domain/shared"
 	userdomain "ddd/domain/user"
+	"ddd/infrastructure/eventbus"
+	"ddd/infrastructure/metrics"
 	"ddd/infrastructure/persistence/mysql"
 	"ddd/infrastructure/persistence/retry"
 	"ddd/pkg/logger"
@@ -25,10 +31,18 @@ import (
 )
 
 type AppBuilder struct {
-	cfg          *config.Config
-	controllers  []api.ControllerRegister
-	middlewares  []api.MiddlewareRegister
-	customRoutes []api.Route
+	cfg              *config.Config
+	controllers      []api.ControllerRegister
+	middlewares      []api.MiddlewareRegister
+	customRoutes     []api.Route
+	eventHandlers    []eventHandlerRegistration
+	eventMiddlewares []shared.EventMiddleware
+}
+
+type eventHandlerRegistration struct {
+	eventName   string
+	handler     shared.EventHandler
+	middlewares []shared.EventMiddleware
 }
 
 func NewBuilder(cfg *config.Config) *AppBuilder {
@@ -56,6 +70,23 @@ func (b *AppBuilder) WithRoute(method, path string, handler gin.HandlerFunc) *Ap
 	return b
 }
 
+// WithEventHandler 订阅进程内事件总线，处理器在写入该事件的事务提交后执行；
+// middlewares 只作用于该订阅，位于全局中间件之内。
+func (b *AppBuilder) WithEventHandler(eventName string, handler shared.EventHandler, middlewares ...shared.EventMiddleware) *AppBuilder {
+	b.eventHandlers = append(b.eventHandlers, eventHandlerRegistration{
+		eventName:   eventName,
+		handler:     handler,
+		middlewares: middlewares,
+	})
+	return b
+}
+
+// WithEventMiddleware 为进程内事件总线的所有订阅追加中间件，位于内置的日志与指标中间件之内。
+func (b *AppBuilder) WithEventMiddleware(middlewares ...shared.EventMiddleware) *AppBuilder {
+	b.eventMiddlewares = append(b.eventMiddlewares, middlewares...)
+	return b
+}
+
 func (b *AppBuilder) Build() *App {
 	if err := logger.Init(&b.cfg.Log, b.cfg.App.Env); err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
@@ -67,12 +98,23 @@ func (b *AppBuilder) Build() *App {
 		zap.String("version", b.cfg.App.Version),
 		zap.String("env", b.cfg.App.Env))
 
-	db, userRepo, orderRepo, uowFactory := b.initMySQLPersistence()
+	eventBus, eventMetrics := b.initEventBus()
+	db, userRepo, orderRepo, uowFactory := b.initMySQLPersistence(eventBus)
 	userService := userapp.NewApplicationService(userRepo, orderRepo, uowFactory)
+	if b.cfg.Projections.Enabled {
+		userService.SetSpendingReader(mysql.NewUserSpendingProjection(db))
+	}
 	orderService := orderapp.NewApplicationService(orderRepo, userRepo, uowFactory)
+	if historyReader, ok := orderRepo.(orderdomain.HistoryReader); ok {
+		orderService.SetHistoryReader(historyReader)
+	}
 
+	outboxMetrics := NewOutboxMetrics(db)
 	if !b.hasHealthController() {
-		b.controllers = append(b.controllers, b.newHealthController(db))
+		b.controllers = append(b.controllers, b.newHealthController(db, outboxMetrics))
+	}
+	if b.cfg.Metrics.Enabled {
+		b.WithRoute(http.MethodGet, "/metrics", gin.WrapH(NewMetricsHandler(NewMetricsRegistry(outboxMetrics, eventMetrics))))
 	}
 	if !b.hasUserController() {
 		b.controllers = append(b.controllers, apiuser.NewController(userService))
@@ -80,6 +122,18 @@ func (b *AppBuilder) Build() *App {
 	if !b.hasOrderController() {
 		b.controllers = append(b.controllers, apiorder.NewController(orderService))
 	}
+	adminEnabled := b.cfg.Admin.Enabled && b.cfg.Admin.Token != ""
+	if b.cfg.Admin.Enabled && !adminEnabled {
+		logger.Warn("Admin API is enabled but admin.token is empty, admin routes are not registered")
+	}
+	if adminEnabled && !b.hasOutboxController() {
+		deadLetterService := outboxapp.NewDeadLetterService(mysql.NewDeadLetterRepository(db))
+		b.controllers = append(b.controllers, apioutbox.NewController(deadLetterService, &b.cfg.Admin))
+	}
+	if adminEnabled && !b.hasEventsController() {
+		subscriptionService := eventsapp.NewSubscriptionService(eventBus)
+		b.controllers = append(b.controllers, apievents.NewController(subscriptionService, &b.cfg.Admin))
+	}
 	router := api.NewRouter(b.cfg, b.controllers, b.middlewares, b.customRoutes)
 	router.SetupRoutes()
 	server := &http.Server{
@@ -90,16 +144,43 @@ func (b *AppBuilder) Build() *App {
 	}
 
 	app := &App{
-		config: b.cfg,
-		router: router,
-		server: server,
-		db:     db,
+		config:   b.cfg,
+		router:   router,
+		server:   server,
+		db:       db,
+		eventBus: eventBus,
+	}
+	if b.cfg.Worker.Embedded {
+		b.initEmbeddedOutboxWorker(app, db, outboxMetrics)
 	}
 
 	return app
 }
 
-func (b *AppBuilder) initMySQLPersistence() (*gorm.DB, userdomain.Repository, orderdomain.Repository, shared.UnitOfWorkFactory) {
+func (b *AppBuilder) initEventBus() (*shared.EventBus, *eventbus.Metrics) {
+	eventMetrics := eventbus.NewMetrics()
+	middlewares := []shared.EventMiddleware{eventbus.Logging()}
+	if b.cfg.Events.Tracing {
+		middlewares = append(middlewares, eventbus.Tracing(eventbus.NewOTelTracer(nil)))
+	}
+	middlewares = append(middlewares, eventMetrics.Middleware())
+	middlewares = append(middlewares, b.eventMiddlewares...)
+	eventBus := NewEventBus(b.cfg, middlewares...)
+
+	for _, registration := range b.eventHandlers {
+		options := NewEventPublishOptions(b.cfg)
+		options.Middlewares = registration.middlewares
+		if _, err := eventBus.SubscribeWithOptions(registration.eventName, registration.handler, options); err != nil {
+			logger.Fatal("Failed to subscribe event handler",
+				zap.String("event_type", registration.eventName),
+				zap.Error(err),
+			)
+		}
+	}
+	return eventBus, eventMetrics
+}
+
+func (b *AppBuilder) initMySQLPersistence(eventBus shared.DomainEventPublisher) (*gorm.DB, userdomain.Repository, orderdomain.Repository, shared.UnitOfWorkFactory) {
 	logger.Info("Using MySQL/GORM persistence layer")
 
 	db, err := NewMySQLConfig(b.cfg).Connect()
@@ -117,15 +198,38 @@ func (b *AppBuilder) initMySQLPersistence() (*gorm.DB, userdomain.Repository, or
 	logger.Info("Connected to MySQL successfully")
 
 	userRepo := mysql.NewUserRepository(db)
-	orderRepo := mysql.NewOrderRepository(db)
+	orderRepo, err := NewOrderRepository(b.cfg, db)
+	if err != nil {
+		logger.Fatal("Failed to create order repository", zap.Error(err))
+	}
 	uowFactory := mysql.NewUnitOfWorkFactory(
 		db,
 		retry.FromAppConfig(b.cfg),
 	)
+	uowFactory.SetEventPublisher(eventBus)
+	if repo, ok := orderRepo.(interface {
+		SetUnitOfWorkFactory(shared.UnitOfWorkFactory)
+	}); ok {
+		repo.SetUnitOfWorkFactory(uowFactory)
+	}
 
 	return db, userRepo, orderRepo, uowFactory
 }
 
+// initEmbeddedOutboxWorker 在 API 进程内挂载 outbox worker，由 App.Run 在选主成功后启动。
+func (b *AppBuilder) initEmbeddedOutboxWorker(app *App, db *gorm.DB, outboxMetrics *metrics.OutboxMetrics) {
+	runtime, err := NewOutboxRuntime(b.cfg, db, outboxMetrics)
+	if err != nil {
+		logger.Fatal("Failed to create embedded outbox worker", zap.Error(err))
+	}
+	elector, err := NewOutboxLeaderElector(b.cfg, db)
+	if err != nil {
+		logger.Fatal("Failed to create outbox leader elector", zap.Error(err))
+	}
+	app.outbox = runtime
+	app.outboxElector = elector
+}
+
 func (b *AppBuilder) hasUserController() bool {
 	for _, c := range b.controllers {
 		if _, ok := c.(*apiuser.Controller); ok {
@@ -144,6 +248,24 @@ func (b *AppBuilder) hasOrderController() bool {
 	return false
 }
 
+func (b *AppBuilder) hasOutboxController() bool {
+	for _, c := range b.controllers {
+		if _, ok := c.(*apioutbox.Controller); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *AppBuilder) hasEventsController() bool {
+	for _, c := range b.controllers {
+		if _, ok := c.(*apievents.Controller); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *AppBuilder) hasHealthController() bool {
 	for _, c := range b.controllers {
 		if _, ok := c.(*health.Controller); ok {
@@ -153,11 +275,15 @@ func (b *AppBuilder) hasHealthController() bool {
 	return false
 }
 
-func (b *AppBuilder) newHealthController(db *gorm.DB) *health.Controller {
+func (b *AppBuilder) newHealthController(db *gorm.DB, outboxMetrics *metrics.OutboxMetrics) *health.Controller {
 	var healthDB interface{}
 	if db != nil {
 		sqlDB, _ := db.DB()
 		healthDB = sqlDB
 	}
-	return health.NewController(b.cfg, healthDB)
+	controller := health.NewController(b.cfg, healthDB)
+	if b.cfg.Metrics.Enabled {
+		controller.WithOutbox(outboxMetrics)
+	}
+	return controller
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"ddd/config"
+	"ddd/domain/shared"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// NewEventBus 按 events 配置创建进程内事件总线，middlewares 作用于所有订阅，
+// 异步处理的最终失败记录为错误日志。
+func NewEventBus(cfg *config.Config, middlewares ...shared.EventMiddleware) *shared.EventBus {
+	return shared.NewEventBusWithOptions(shared.EventBusOptions{
+		Async:          cfg.Events.Async,
+		QueueSize:      cfg.Events.QueueSize,
+		Workers:        cfg.Events.Workers,
+		DefaultOptions: NewEventPublishOptions(cfg),
+		Middlewares:    middlewares,
+		OnError: func(event shared.DomainEvent, handler string, err error) {
+			logger.Error("In-process event handler failed",
+				zap.String("event_type", event.EventName()),
+				zap.String("aggregate_id", event.GetAggregateID()),
+				zap.String("handler", handler),
+				zap.Error(err),
+			)
+		},
+	})
+}
+
+// NewEventPublishOptions 返回 events 配置中的默认重试与超时。
+func NewEventPublishOptions(cfg *config.Config) shared.EventPublishOptions {
+	return shared.EventPublishOptions{
+		Retry:   cfg.Events.Retry,
+		Timeout: cfg.Events.Timeout,
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ddd/infrastructure/metrics"
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const metricsShutdownTimeout = 5 * time.Second
+
+func NewOutboxMetrics(db *gorm.DB) *metrics.OutboxMetrics {
+	return metrics.NewOutboxMetrics(mysql.NewOutboxRepository(db))
+}
+
+// NewMetricsRegistry 注册 Go 运行时、进程指标以及传入的业务指标（outbox、事件处理等）。
+func NewMetricsRegistry(appCollectors ...prometheus.Collector) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	registry.MustRegister(appCollectors...)
+	return registry
+}
+
+// NewMetricsHandler 在部分指标采集失败时仍返回其余指标，避免数据库抖动导致整次抓取失败。
+func NewMetricsHandler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ServeMetrics 在 addr 上暴露 /metrics，ctx 取消后关闭。
+func ServeMetrics(ctx context.Context, addr string, registry *prometheus.Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", NewMetricsHandler(registry))
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Failed to shut down metrics server", zap.Error(err))
+		}
+	})
+	defer stop()
+
+	logger.Info("Metrics server started", zap.String("addr", addr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
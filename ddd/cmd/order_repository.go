@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ddd/config"
+	orderdomain "ddd/domain/order"
+	"ddd/infrastructure/persistence/mysql"
+
+	"gorm.io/gorm"
+)
+
+// NewOrderRepository 按 database.order_store 配置选择订单的持久化方式。
+func NewOrderRepository(cfg *config.Config, db *gorm.DB) (orderdomain.Repository, error) {
+	switch cfg.Database.OrderStore {
+	case "state", "":
+		return mysql.NewOrderRepository(db), nil
+	case "event_sourced":
+		repo := mysql.NewEventSourcedOrderRepository(db)
+		if cfg.Database.Snapshots.Enabled {
+			repo.SetSnapshotStore(mysql.NewSnapshotStore(db, cfg.Database.Snapshots.Frequency))
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unsupported order store: %s", cfg.Database.OrderStore)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ddd/config"
+	"ddd/infrastructure/messaging/cloudevents"
+	"ddd/infrastructure/messaging/nats"
+	"ddd/infrastructure/messaging/webhook"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+// NewOutboxPublisher 按 worker.publisher 配置构建 Outbox 事件投递实现。
+func NewOutboxPublisher(cfg *config.Config) (mysql.OutboxPublisher, error) {
+	switch cfg.Worker.Publisher {
+	case "logging", "":
+		return &mysql.LoggingOutboxPublisher{}, nil
+	case "webhook":
+		webhookCfg, err := NewWebhookConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return webhook.NewPublisher(webhookCfg)
+	case "nats":
+		natsCfg, err := NewNATSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return nats.NewPublisher(natsCfg)
+	default:
+		return nil, fmt.Errorf("unsupported outbox publisher: %s", cfg.Worker.Publisher)
+	}
+}
+
+func NewWebhookConfig(cfg *config.Config) (webhook.Config, error) {
+	webhookCfg := cfg.Worker.Webhook
+	endpoints := make([]webhook.Endpoint, len(webhookCfg.Endpoints))
+	for i, endpoint := range webhookCfg.Endpoints {
+		endpoints[i] = webhook.Endpoint{
+			Pattern: endpoint.Pattern,
+			URL:     endpoint.URL,
+			Secret:  endpoint.Secret,
+		}
+	}
+	envelope, err := newCloudEventsMode(webhookCfg.Envelope)
+	if err != nil {
+		return webhook.Config{}, err
+	}
+	return webhook.Config{
+		Endpoints: endpoints,
+		Secret:    webhookCfg.Secret,
+		Timeout:   webhookCfg.Timeout,
+		Envelope:  envelope,
+		Source:    cloudEventsSource(cfg, webhookCfg.Source),
+	}, nil
+}
+
+func NewNATSConfig(cfg *config.Config) (nats.Config, error) {
+	natsCfg := cfg.Worker.NATS
+	subjects := make([]nats.SubjectMapping, len(natsCfg.Subjects))
+	for i, mapping := range natsCfg.Subjects {
+		subjects[i] = nats.SubjectMapping{
+			Pattern: mapping.Pattern,
+			Subject: mapping.Subject,
+		}
+	}
+	envelope, err := newCloudEventsMode(natsCfg.Envelope)
+	if err != nil {
+		return nats.Config{}, err
+	}
+	return nats.Config{
+		URL:           natsCfg.URL,
+		Subjects:      subjects,
+		SubjectPrefix: natsCfg.SubjectPrefix,
+		AckTimeout:    natsCfg.AckTimeout,
+		Envelope:      envelope,
+		Source:        cloudEventsSource(cfg, natsCfg.Source),
+	}, nil
+}
+
+// newCloudEventsMode 将 envelope 配置转换为 CloudEvents 内容模式，none 表示不封装。
+func newCloudEventsMode(envelope string) (cloudevents.Mode, error) {
+	if envelope == "" || envelope == "none" {
+		return "", nil
+	}
+	return cloudevents.ParseMode(envelope)
+}
+
+func cloudEventsSource(cfg *config.Config, source string) string {
+	if source != "" {
+		return source
+	}
+	return "/" + cfg.App.Name
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"ddd/config"
+	"ddd/infrastructure/metrics"
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OutboxRuntime 组合 outbox worker、保留任务与发布器，供独立 worker 进程与 API 进程内嵌运行共用。
+type OutboxRuntime struct {
+	cfg          *config.Config
+	worker       *mysql.OutboxWorker
+	retentionJob *mysql.OutboxRetentionJob
+	publisher    mysql.OutboxPublisher
+}
+
+// NewOutboxRuntime 创建 outbox 运行时，outboxMetrics 非空时由 worker 上报投递指标。
+func NewOutboxRuntime(cfg *config.Config, db *gorm.DB, outboxMetrics *metrics.OutboxMetrics) (*OutboxRuntime, error) {
+	publisher, err := NewOutboxPublisher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox publisher: %w", err)
+	}
+
+	workerConfig := NewOutboxWorkerConfig(cfg)
+	if outboxMetrics != nil {
+		workerConfig.Metrics = outboxMetrics
+	}
+	worker, err := mysql.NewOutboxWorker(
+		mysql.NewOutboxRepository(db),
+		publisher,
+		workerConfig,
+	)
+	if err != nil {
+		closePublisher(publisher)
+		return nil, fmt.Errorf("failed to create outbox worker: %w", err)
+	}
+
+	retentionJob, err := NewOutboxRetentionJob(cfg, db)
+	if err != nil {
+		closePublisher(publisher)
+		return nil, fmt.Errorf("failed to create outbox retention job: %w", err)
+	}
+
+	return &OutboxRuntime{
+		cfg:          cfg,
+		worker:       worker,
+		retentionJob: retentionJob,
+		publisher:    publisher,
+	}, nil
+}
+
+func (r *OutboxRuntime) Worker() *mysql.OutboxWorker {
+	return r.worker
+}
+
+// Run 运行 worker 与保留任务直到 ctx 取消，返回前等待两者退出。
+func (r *OutboxRuntime) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	if r.retentionJob != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.retentionJob.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Outbox retention job exited with error", zap.Error(err))
+			}
+		}()
+		logger.Info("Outbox retention job started",
+			zap.Int("retain_days", r.cfg.Worker.Retention.RetainDays),
+			zap.String("archive", r.cfg.Worker.Retention.Archive),
+		)
+	}
+	defer wg.Wait()
+
+	logger.Info("Outbox worker started",
+		zap.String("worker_id", r.worker.WorkerID()),
+		zap.Duration("poll_interval", r.cfg.Worker.PollInterval),
+		zap.Int("batch_size", r.cfg.Worker.BatchSize),
+		zap.Int("concurrency", r.cfg.Worker.Concurrency),
+		zap.Int("max_retries", r.cfg.Worker.MaxRetries),
+		zap.Duration("lease_duration", r.cfg.Worker.LeaseDuration),
+		zap.String("publisher", r.cfg.Worker.Publisher),
+	)
+
+	err := r.worker.Run(ctx)
+	logger.Info("Outbox worker stopped")
+	return err
+}
+
+// Close 释放发布器持有的连接。
+func (r *OutboxRuntime) Close() {
+	closePublisher(r.publisher)
+}
+
+func closePublisher(publisher mysql.OutboxPublisher) {
+	if closer, ok := publisher.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Warn("Failed to close outbox publisher", zap.Error(err))
+		}
+	}
+}
+
+// NewOutboxLeaderElector 按 worker.leader_election 配置构建进程内 worker 的主节点选举。
+func NewOutboxLeaderElector(cfg *config.Config, db *gorm.DB) (*mysql.LeaderElector, error) {
+	return mysql.NewLeaderElector(db, mysql.LeaderElectionConfig{
+		LockName:      cfg.Worker.LeaderElection.LockName,
+		RetryInterval: cfg.Worker.LeaderElection.RetryInterval,
+		CheckInterval: cfg.Worker.LeaderElection.CheckInterval,
+	})
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"ddd/config"
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/infrastructure/persistence/retry"
+
+	"gorm.io/gorm"
+)
+
+func NewOutboxWorkerConfig(cfg *config.Config) mysql.OutboxWorkerConfig {
+	return mysql.OutboxWorkerConfig{
+		PollInterval:   cfg.Worker.PollInterval,
+		BatchSize:      cfg.Worker.BatchSize,
+		MaxRetries:     cfg.Worker.MaxRetries,
+		WorkerID:       cfg.Worker.WorkerID,
+		LeaseDuration:  cfg.Worker.LeaseDuration,
+		ReaperInterval: cfg.Worker.ReaperInterval,
+		Ordering:       mysql.OutboxOrderingMode(cfg.Worker.OrderingMode),
+		Concurrency:    cfg.Worker.Concurrency,
+		DrainTimeout:   cfg.Worker.DrainTimeout,
+		RetryBackoff: retry.Config{
+			InitialDelay:  cfg.Worker.RetryBackoff.InitialDelay,
+			MaxDelay:      cfg.Worker.RetryBackoff.MaxDelay,
+			BackoffFactor: cfg.Worker.RetryBackoff.BackoffFactor,
+			JitterEnabled: cfg.Worker.RetryBackoff.JitterEnabled,
+		},
+	}
+}
+
+// NewOutboxRetentionJob 按 worker.retention 配置构建保留任务，未启用时返回 nil。
+func NewOutboxRetentionJob(cfg *config.Config, db *gorm.DB) (*mysql.OutboxRetentionJob, error) {
+	retentionCfg := cfg.Worker.Retention
+	if !retentionCfg.Enabled {
+		return nil, nil
+	}
+
+	var archiver mysql.OutboxArchiver
+	switch retentionCfg.Archive {
+	case "table":
+		archiver = &mysql.TableOutboxArchiver{}
+	case "file":
+		fileArchiver, err := mysql.NewFileOutboxArchiver(retentionCfg.ArchiveDir)
+		if err != nil {
+			return nil, err
+		}
+		archiver = fileArchiver
+	case "none", "":
+	default:
+		return nil, fmt.Errorf("unsupported outbox archive mode: %s", retentionCfg.Archive)
+	}
+
+	return mysql.NewOutboxRetentionJob(db, archiver, mysql.OutboxRetentionConfig{
+		Retention:  time.Duration(retentionCfg.RetainDays) * 24 * time.Hour,
+		Interval:   retentionCfg.Interval,
+		BatchSize:  retentionCfg.BatchSize,
+		BatchPause: retentionCfg.BatchPause,
+	})
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ddd/config"
+	"ddd/infrastructure/persistence/mysql"
+
+	"gorm.io/gorm"
+)
+
+// NewProjectionEngine 按 projections 配置构建投影引擎并注册全部内置投影。
+func NewProjectionEngine(cfg *config.Config, db *gorm.DB) (*mysql.ProjectionEngine, error) {
+	source, err := NewProjectionSource(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	engine := mysql.NewProjectionEngine(db, source, mysql.ProjectionEngineConfig{
+		BatchSize:    cfg.Projections.BatchSize,
+		PollInterval: cfg.Projections.PollInterval,
+	})
+	if err := engine.Register(mysql.NewUserSpendingProjection(db)); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+func NewProjectionSource(cfg *config.Config, db *gorm.DB) (mysql.ProjectionSource, error) {
+	switch cfg.Projections.Source {
+	case "outbox", "":
+		return mysql.NewOutboxProjectionSource(db, cfg.Projections.SettleDelay), nil
+	case "event_store":
+		return mysql.NewEventStoreProjectionSource(db, cfg.Projections.SettleDelay), nil
+	default:
+		return nil, fmt.Errorf("unsupported projection source: %s", cfg.Projections.Source)
+	}
+}
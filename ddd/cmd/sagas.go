@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	orderapp "ddd/application/order"
+	"ddd/config"
+	"ddd/infrastructure/fulfilment"
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/infrastructure/persistence/retry"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SagaRuntime 组合 saga 管理器与为其接收事件的投影引擎（检查点为 mysql.SagaIntakeName）。
+type SagaRuntime struct {
+	manager *mysql.SagaManager
+	intake  *mysql.ProjectionEngine
+}
+
+// NewSagaRuntime 按 sagas 配置构建 saga 管理器并注册订单履约 saga。
+func NewSagaRuntime(cfg *config.Config, db *gorm.DB) (*SagaRuntime, error) {
+	manager := mysql.NewSagaManager(db, mysql.SagaManagerConfig{
+		PollInterval:            cfg.Sagas.PollInterval,
+		BatchSize:               cfg.Sagas.BatchSize,
+		MaxStartAge:             cfg.Sagas.MaxStartAge,
+		CompensationMaxAttempts: cfg.Sagas.CompensationMaxAttempts,
+	})
+
+	orderRepo, err := NewOrderRepository(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	orderService := orderapp.NewApplicationService(
+		orderRepo,
+		mysql.NewUserRepository(db),
+		mysql.NewUnitOfWorkFactory(db, retry.FromAppConfig(cfg)),
+	)
+	fulfilmentSaga := orderapp.NewFulfilmentSaga(
+		orderService,
+		fulfilment.NewSimulatedInventory(),
+		fulfilment.NewSimulatedPayments(cfg.Sagas.OrderFulfilment.PaymentLimit),
+		orderapp.FulfilmentSagaConfig{
+			StepTimeout: cfg.Sagas.OrderFulfilment.StepTimeout,
+			MaxAttempts: cfg.Sagas.OrderFulfilment.MaxAttempts,
+		},
+	)
+	if err := manager.Register(fulfilmentSaga.Definition()); err != nil {
+		return nil, err
+	}
+
+	source, err := NewProjectionSource(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	intake := mysql.NewProjectionEngine(db, source, mysql.ProjectionEngineConfig{
+		BatchSize:    cfg.Projections.BatchSize,
+		PollInterval: cfg.Sagas.PollInterval,
+	})
+	if err := intake.Register(manager); err != nil {
+		return nil, err
+	}
+	return &SagaRuntime{manager: manager, intake: intake}, nil
+}
+
+func (r *SagaRuntime) Manager() *mysql.SagaManager {
+	return r.manager
+}
+
+// Run 同时运行事件接收与步骤执行，直到 ctx 取消，返回前等待两者退出。
+func (r *SagaRuntime) Run(ctx context.Context) error {
+	logger.Warn("Order fulfilment saga uses simulated inventory and payment services")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := r.intake.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Saga event intake exited with error", zap.Error(err))
+		}
+	}()
+
+	err := r.manager.Run(ctx)
+	wg.Wait()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("saga manager exited with error: %w", err)
+	}
+	return nil
+}
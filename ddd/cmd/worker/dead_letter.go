@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	outboxapp "ddd/application/outbox"
+	"ddd/cmd"
+	"ddd/config"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+const deadLetterUsage = `Usage: worker dlq <command> [flags] [event IDs...]
+
+Commands:
+  list     List FAILED events, filter with -type / -aggregate
+  show     Show payload and last error of one event: dlq show <id>
+  replay   Move events back to PENDING: dlq replay [-note ...] <id>... | -type/-aggregate [-limit n]
+  discard  Mark events DISCARDED with an audit note: dlq discard -note "..." <id>...
+`
+
+const deadLetterCommandTimeout = time.Minute
+
+func runDeadLetterCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, deadLetterUsage)
+		return fmt.Errorf("dlq command is required")
+	}
+	if args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stdout, deadLetterUsage)
+		return nil
+	}
+
+	db, err := cmd.NewMySQLConfig(cfg).Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	service := outboxapp.NewDeadLetterService(mysql.NewDeadLetterRepository(db))
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterCommandTimeout)
+	defer cancel()
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		return listDeadLetters(ctx, service, args)
+	case "show":
+		return showDeadLetter(ctx, service, args)
+	case "replay":
+		return replayDeadLetters(ctx, service, args)
+	case "discard":
+		return discardDeadLetters(ctx, service, args)
+	default:
+		fmt.Fprint(os.Stderr, deadLetterUsage)
+		return fmt.Errorf("unknown dlq command: %s", command)
+	}
+}
+
+func listDeadLetters(ctx context.Context, service *outboxapp.DeadLetterService, args []string) error {
+	fs := flag.NewFlagSet("dlq list", flag.ContinueOnError)
+	eventType := fs.String("type", "", "Filter by event type")
+	aggregateID := fs.String("aggregate", "", "Filter by aggregate ID")
+	page := fs.Int("page", 1, "Page number")
+	pageSize := fs.Int("page-size", 50, "Page size")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resp, err := service.ListDeadLetters(ctx, outboxapp.ListDeadLettersRequest{
+		EventType:   *eventType,
+		AggregateID: *aggregateID,
+		Page:        *page,
+		PageSize:    *pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEVENT TYPE\tAGGREGATE ID\tSEQ\tRETRIES\tUPDATED AT\tLAST ERROR")
+	for _, item := range resp.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			item.ID, item.EventType, item.AggregateID, item.AggregateSequence,
+			item.RetryCount, item.UpdatedAt.Format(time.RFC3339), truncate(item.LastError, 80))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d of %d failed events (page %d)\n", len(resp.Items), resp.Total, resp.Page)
+	return nil
+}
+
+func showDeadLetter(ctx context.Context, service *outboxapp.DeadLetterService, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: worker dlq show <event ID>")
+	}
+
+	resp, err := service.GetDeadLetter(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		*outboxapp.DeadLetterResponse
+		Payload json.RawMessage `json:"payload"`
+	}{DeadLetterResponse: resp, Payload: json.RawMessage(resp.Payload)}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func replayDeadLetters(ctx context.Context, service *outboxapp.DeadLetterService, args []string) error {
+	fs := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+	eventType := fs.String("type", "", "Replay failed events of this type when no IDs are given")
+	aggregateID := fs.String("aggregate", "", "Replay failed events of this aggregate when no IDs are given")
+	limit := fs.Int("limit", 100, "Maximum events to replay when replaying by filter")
+	note := fs.String("note", "", "Audit note")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resp, err := service.ReplayDeadLetters(ctx, outboxapp.ReplayDeadLettersRequest{
+		IDs:         fs.Args(),
+		EventType:   *eventType,
+		AggregateID: *aggregateID,
+		Limit:       *limit,
+		Operator:    currentOperator(),
+		Note:        *note,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replayed %d of %d requested events\n", resp.Affected, resp.Requested)
+	return nil
+}
+
+func discardDeadLetters(ctx context.Context, service *outboxapp.DeadLetterService, args []string) error {
+	fs := flag.NewFlagSet("dlq discard", flag.ContinueOnError)
+	note := fs.String("note", "", "Audit note (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resp, err := service.DiscardDeadLetters(ctx, outboxapp.DiscardDeadLettersRequest{
+		IDs:      fs.Args(),
+		Operator: currentOperator(),
+		Note:     *note,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Discarded %d of %d requested events\n", resp.Affected, resp.Requested)
+	return nil
+}
+
+func currentOperator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
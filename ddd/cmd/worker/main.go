@@ -10,12 +10,23 @@ import (
 
 	"ddd/cmd"
 	"ddd/config"
-	"ddd/infrastructure/persistence/mysql"
 	"ddd/pkg/logger"
 
 	"go.uber.org/zap"
 )
 
+const usage = `Usage: worker [-config path] [command]
+
+Commands:
+  run                      Run the outbox worker (default)
+  purge                    Archive and delete PUBLISHED events past worker.retention once
+  dlq list|show|replay|discard
+                           Manage FAILED outbox events, see "worker dlq -h"
+  projections status|rebuild|run
+                           Manage read model projections, see "worker projections -h"
+  sagas list|retry|run     Manage saga instances, see "worker sagas -h"
+`
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Printf("Worker startup failed: %v\n", err)
@@ -24,7 +35,7 @@ func main() {
 }
 
 func run() error {
-	configPath := parseConfigPath()
+	configPath, args := parseArgs()
 
 	cfg, err := config.Load(configPath)
 	if err != nil {
@@ -35,6 +46,29 @@ func run() error {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
+	command := "run"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "run":
+		return runWorker(cfg)
+	case "purge":
+		return runPurge(cfg)
+	case "dlq":
+		return runDeadLetterCommand(cfg, args)
+	case "projections":
+		return runProjectionsCommand(cfg, args)
+	case "sagas":
+		return runSagasCommand(cfg, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+func runWorker(cfg *config.Config) error {
 	if !cfg.Worker.Enabled {
 		logger.Info("Outbox worker is disabled by config; exiting")
 		return nil
@@ -45,37 +79,87 @@ func run() error {
 		return fmt.Errorf("failed to connect to MySQL: %w", err)
 	}
 
-	worker, err := mysql.NewOutboxWorker(
-		mysql.NewOutboxRepository(db),
-		&mysql.LoggingOutboxPublisher{},
-		cfg.Worker.PollInterval,
-		cfg.Worker.BatchSize,
-		cfg.Worker.MaxRetries,
-	)
+	outboxMetrics := cmd.NewOutboxMetrics(db)
+	runtime, err := cmd.NewOutboxRuntime(cfg, db, outboxMetrics)
 	if err != nil {
-		return fmt.Errorf("failed to create outbox worker: %w", err)
+		return err
 	}
+	defer runtime.Close()
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	logger.Info("Outbox worker started",
-		zap.Duration("poll_interval", cfg.Worker.PollInterval),
-		zap.Int("batch_size", cfg.Worker.BatchSize),
-		zap.Int("max_retries", cfg.Worker.MaxRetries),
-	)
+	if cfg.Metrics.Enabled && cfg.Metrics.WorkerListenAddr != "" {
+		registry := cmd.NewMetricsRegistry(outboxMetrics)
+		go func() {
+			if err := cmd.ServeMetrics(ctx, cfg.Metrics.WorkerListenAddr, registry); err != nil {
+				logger.Error("Metrics server exited with error", zap.Error(err))
+			}
+		}()
+	}
 
-	if err := worker.Run(ctx); err != nil && err != context.Canceled {
+	if cfg.Projections.Enabled {
+		engine, err := cmd.NewProjectionEngine(cfg, db)
+		if err != nil {
+			return fmt.Errorf("failed to create projection engine: %w", err)
+		}
+		go func() {
+			if err := engine.Run(ctx); err != nil && err != context.Canceled {
+				logger.Error("Projection engine exited with error", zap.Error(err))
+			}
+		}()
+	}
+
+	if cfg.Sagas.Enabled {
+		sagaRuntime, err := cmd.NewSagaRuntime(cfg, db)
+		if err != nil {
+			return fmt.Errorf("failed to create saga runtime: %w", err)
+		}
+		go func() {
+			if err := sagaRuntime.Run(ctx); err != nil {
+				logger.Error("Saga runtime exited with error", zap.Error(err))
+			}
+		}()
+	}
+
+	if err := runtime.Run(ctx); err != nil && err != context.Canceled {
 		return fmt.Errorf("outbox worker exited with error: %w", err)
 	}
+	return nil
+}
 
-	logger.Info("Outbox worker stopped")
+func runPurge(cfg *config.Config) error {
+	db, err := cmd.NewMySQLConfig(cfg).Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+
+	retentionJob, err := cmd.NewOutboxRetentionJob(cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox retention job: %w", err)
+	}
+	if retentionJob == nil {
+		return fmt.Errorf("worker.retention.enabled is false; nothing to purge")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	deleted, err := retentionJob.PurgeOnce(ctx)
+	if err != nil {
+		return fmt.Errorf("outbox purge failed after deleting %d events: %w", deleted, err)
+	}
+	fmt.Printf("Purged %d published outbox events\n", deleted)
 	return nil
 }
 
-func parseConfigPath() string {
+func parseArgs() (string, []string) {
 	var configPath string
 	flag.StringVar(&configPath, "config", "", "Path to config file")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
-	return configPath
+	return configPath, flag.Args()
 }
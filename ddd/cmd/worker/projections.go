@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"ddd/cmd"
+	"ddd/config"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+const projectionsUsage = `Usage: worker projections <command> [name]
+
+Commands:
+  status          Show checkpoint position and processed count of every projection
+  rebuild <name>  Truncate the read model and replay it from the beginning of the source
+  run             Keep projections up to date until interrupted
+`
+
+const projectionStatusTimeout = time.Minute
+
+func runProjectionsCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, projectionsUsage)
+		return fmt.Errorf("projections command is required")
+	}
+	if args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stdout, projectionsUsage)
+		return nil
+	}
+
+	db, err := cmd.NewMySQLConfig(cfg).Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	engine, err := cmd.NewProjectionEngine(cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to create projection engine: %w", err)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "status":
+		ctx, cancel := context.WithTimeout(context.Background(), projectionStatusTimeout)
+		defer cancel()
+		return printProjectionStatus(ctx, engine)
+	case "rebuild":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: worker projections rebuild <name>")
+		}
+		// 重建耗时与事件量成正比，不设超时，可通过信号中断后再次执行。
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		processed, err := engine.Rebuild(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("projection rebuild failed after %d events: %w", processed, err)
+		}
+		fmt.Printf("Rebuilt projection %s from %d events\n", args[0], processed)
+		return nil
+	case "run":
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		if err := engine.Run(ctx); err != nil && err != context.Canceled {
+			return fmt.Errorf("projection engine exited with error: %w", err)
+		}
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, projectionsUsage)
+		return fmt.Errorf("unknown projections command: %s", command)
+	}
+}
+
+func printProjectionStatus(ctx context.Context, engine *mysql.ProjectionEngine) error {
+	statuses, err := engine.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tPOSITION\tPROCESSED\tUPDATED AT")
+	for _, status := range statuses {
+		position, updatedAt := status.Position, "-"
+		if position == "" {
+			position = "-"
+		}
+		if !status.UpdatedAt.IsZero() {
+			updatedAt = status.UpdatedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", status.Name, status.Source, position, status.Processed, updatedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"ddd/cmd"
+	"ddd/config"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+const sagasUsage = `Usage: worker sagas <command> [flags]
+
+Commands:
+  list        List saga instances, filter with -type / -status / -correlation
+  retry <id>  Resume compensation of a FAILED saga
+  run         Run sagas until interrupted
+`
+
+const sagaCommandTimeout = time.Minute
+
+func runSagasCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, sagasUsage)
+		return fmt.Errorf("sagas command is required")
+	}
+	if args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stdout, sagasUsage)
+		return nil
+	}
+
+	db, err := cmd.NewMySQLConfig(cfg).Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	runtime, err := cmd.NewSagaRuntime(cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to create saga runtime: %w", err)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		ctx, cancel := context.WithTimeout(context.Background(), sagaCommandTimeout)
+		defer cancel()
+		return listSagas(ctx, runtime.Manager(), args)
+	case "retry":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: worker sagas retry <saga ID>")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sagaCommandTimeout)
+		defer cancel()
+		if err := runtime.Manager().RetrySaga(ctx, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Saga %s will resume compensation\n", args[0])
+		return nil
+	case "run":
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return runtime.Run(ctx)
+	default:
+		fmt.Fprint(os.Stderr, sagasUsage)
+		return fmt.Errorf("unknown sagas command: %s", command)
+	}
+}
+
+func listSagas(ctx context.Context, manager *mysql.SagaManager, args []string) error {
+	fs := flag.NewFlagSet("sagas list", flag.ContinueOnError)
+	sagaType := fs.String("type", "", "Filter by saga type")
+	status := fs.String("status", "", "Filter by status (RUNNING, COMPENSATING, COMPLETED, COMPENSATED, FAILED)")
+	correlationID := fs.String("correlation", "", "Filter by correlation ID, e.g. an order ID")
+	limit := fs.Int("limit", 50, "Maximum instances to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	instances, err := manager.ListSagas(ctx, mysql.SagaFilter{
+		Type:          *sagaType,
+		Status:        *status,
+		CorrelationID: *correlationID,
+		Limit:         *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tCORRELATION ID\tSTATUS\tSTEP\tATTEMPTS\tUPDATED AT\tREASON\tLAST ERROR")
+	for _, instance := range instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			instance.ID, instance.Type, instance.CorrelationID, instance.Status, instance.Step,
+			instance.Attempts, instance.UpdatedAt.Format(time.RFC3339),
+			truncate(instance.AbortReason, 60), truncate(instance.LastError, 80))
+	}
+	return w.Flush()
+}
@@ -9,12 +9,17 @@ import (
 )
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Worker   WorkerConfig   `mapstructure:"worker"`
-	Log      LogConfig      `mapstructure:"log"`
-	CORS     CORSConfig     `mapstructure:"cors"`
+	App         AppConfig         `mapstructure:"app"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Worker      WorkerConfig      `mapstructure:"worker"`
+	Log         LogConfig         `mapstructure:"log"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Projections ProjectionsConfig `mapstructure:"projections"`
+	Sagas       SagasConfig       `mapstructure:"sagas"`
 }
 type AppConfig struct {
 	Name    string `mapstructure:"name"`
@@ -35,16 +40,24 @@ type RateLimitConfig struct {
 	Burst   int     `mapstructure:"burst"`
 }
 type DatabaseConfig struct {
-	Host            string        `mapstructure:"host"`
-	Port            string        `mapstructure:"port"`
-	Username        string        `mapstructure:"username"`
-	Password        string        `mapstructure:"password"`
-	Database        string        `mapstructure:"database"`
-	LogLevel        string        `mapstructure:"log_level"`
-	MaxOpenConns    int           `mapstructure:"max_open_conns"`
-	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
-	Retry           RetryConfig   `mapstructure:"retry"`
+	Host            string         `mapstructure:"host"`
+	Port            string         `mapstructure:"port"`
+	Username        string         `mapstructure:"username"`
+	Password        string         `mapstructure:"password"`
+	Database        string         `mapstructure:"database"`
+	LogLevel        string         `mapstructure:"log_level"`
+	MaxOpenConns    int            `mapstructure:"max_open_conns"`
+	MaxIdleConns    int            `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration  `mapstructure:"conn_max_lifetime"`
+	Retry           RetryConfig    `mapstructure:"retry"`
+	OrderStore      string         `mapstructure:"order_store"`
+	Snapshots       SnapshotConfig `mapstructure:"snapshots"`
+}
+
+// SnapshotConfig 控制事件溯源聚合的快照，Frequency 为各聚合类型每多少个事件保存一次快照。
+type SnapshotConfig struct {
+	Enabled   bool           `mapstructure:"enabled"`
+	Frequency map[string]int `mapstructure:"frequency"`
 }
 type RetryConfig struct {
 	Enabled                       bool          `mapstructure:"enabled"`
@@ -58,10 +71,68 @@ type RetryConfig struct {
 	RetryOnLockTimeout            bool          `mapstructure:"retry_on_lock_timeout"`
 }
 type WorkerConfig struct {
-	Enabled      bool          `mapstructure:"enabled"`
-	PollInterval time.Duration `mapstructure:"poll_interval"`
-	BatchSize    int           `mapstructure:"batch_size"`
-	MaxRetries   int           `mapstructure:"max_retries"`
+	Enabled        bool            `mapstructure:"enabled"`
+	PollInterval   time.Duration   `mapstructure:"poll_interval"`
+	BatchSize      int             `mapstructure:"batch_size"`
+	MaxRetries     int             `mapstructure:"max_retries"`
+	WorkerID       string          `mapstructure:"worker_id"`
+	LeaseDuration  time.Duration   `mapstructure:"lease_duration"`
+	ReaperInterval time.Duration   `mapstructure:"reaper_interval"`
+	OrderingMode   string          `mapstructure:"ordering_mode"`
+	Concurrency    int             `mapstructure:"concurrency"`
+	DrainTimeout   time.Duration   `mapstructure:"drain_timeout"`
+	RetryBackoff   BackoffConfig   `mapstructure:"retry_backoff"`
+	Retention      RetentionConfig `mapstructure:"retention"`
+	Publisher      string          `mapstructure:"publisher"`
+	Webhook        WebhookConfig   `mapstructure:"webhook"`
+	NATS           NATSConfig      `mapstructure:"nats"`
+	// Embedded 为 true 时 API 进程内启动 worker，多副本通过 LeaderElection 保证同一时刻只有一个在轮询。
+	Embedded       bool                 `mapstructure:"embedded"`
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"`
+}
+type LeaderElectionConfig struct {
+	LockName      string        `mapstructure:"lock_name"`
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+type WebhookConfig struct {
+	Secret    string                  `mapstructure:"secret"`
+	Timeout   time.Duration           `mapstructure:"timeout"`
+	Endpoints []WebhookEndpointConfig `mapstructure:"endpoints"`
+	Envelope  string                  `mapstructure:"envelope"`
+	Source    string                  `mapstructure:"source"`
+}
+type NATSConfig struct {
+	URL           string              `mapstructure:"url"`
+	SubjectPrefix string              `mapstructure:"subject_prefix"`
+	AckTimeout    time.Duration       `mapstructure:"ack_timeout"`
+	Subjects      []NATSSubjectConfig `mapstructure:"subjects"`
+	Envelope      string              `mapstructure:"envelope"`
+	Source        string              `mapstructure:"source"`
+}
+type NATSSubjectConfig struct {
+	Pattern string `mapstructure:"pattern"`
+	Subject string `mapstructure:"subject"`
+}
+type WebhookEndpointConfig struct {
+	Pattern string `mapstructure:"pattern"`
+	URL     string `mapstructure:"url"`
+	Secret  string `mapstructure:"secret"`
+}
+type RetentionConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	RetainDays int           `mapstructure:"retain_days"`
+	Interval   time.Duration `mapstructure:"interval"`
+	BatchSize  int           `mapstructure:"batch_size"`
+	BatchPause time.Duration `mapstructure:"batch_pause"`
+	Archive    string        `mapstructure:"archive"`
+	ArchiveDir string        `mapstructure:"archive_dir"`
+}
+type BackoffConfig struct {
+	InitialDelay  time.Duration `mapstructure:"initial_delay"`
+	MaxDelay      time.Duration `mapstructure:"max_delay"`
+	BackoffFactor float64       `mapstructure:"backoff_factor"`
+	JitterEnabled bool          `mapstructure:"jitter_enabled"`
 }
 type LogConfig struct {
 	Level    string `mapstructure:"level"`
@@ -69,6 +140,51 @@ type LogConfig struct {
 	Output   string `mapstructure:"output"`
 	FilePath string `mapstructure:"file_path"`
 }
+type AdminConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"`
+}
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WorkerListenAddr 为独立 worker 进程暴露 /metrics 的地址，为空时不监听。
+	WorkerListenAddr string `mapstructure:"worker_listen_addr"`
+}
+
+// EventsConfig 配置进程内事件总线，UnitOfWork 提交成功后经它分发领域事件。
+type EventsConfig struct {
+	Async        bool          `mapstructure:"async"`
+	QueueSize    int           `mapstructure:"queue_size"`
+	Workers      int           `mapstructure:"workers"`
+	Retry        int           `mapstructure:"retry"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+	// Tracing 为每次处理创建 OpenTelemetry span，使用全局 TracerProvider。
+	Tracing bool `mapstructure:"tracing"`
+}
+type ProjectionsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Source       string        `mapstructure:"source"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	SettleDelay  time.Duration `mapstructure:"settle_delay"`
+}
+
+// SagasConfig 配置 saga 管理器。saga 经投影引擎接收事件，事件源与 projections.source 相同。
+type SagasConfig struct {
+	Enabled                 bool                  `mapstructure:"enabled"`
+	PollInterval            time.Duration         `mapstructure:"poll_interval"`
+	BatchSize               int                   `mapstructure:"batch_size"`
+	MaxStartAge             time.Duration         `mapstructure:"max_start_age"`
+	CompensationMaxAttempts int                   `mapstructure:"compensation_max_attempts"`
+	OrderFulfilment         OrderFulfilmentConfig `mapstructure:"order_fulfilment"`
+}
+
+// OrderFulfilmentConfig 配置订单履约 saga；库存与支付目前只有模拟实现。
+type OrderFulfilmentConfig struct {
+	StepTimeout  time.Duration `mapstructure:"step_timeout"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	PaymentLimit int64         `mapstructure:"payment_limit"`
+}
 type CORSConfig struct {
 	AllowOrigins     []string `mapstructure:"allow_origins"`
 	AllowMethods     []string `mapstructure:"allow_methods"`
@@ -138,6 +254,11 @@ func setDefaults(v *viper.Viper) {
 	setWorkerDefaults(v)
 	setLogDefaults(v)
 	setCORSDefaults(v)
+	setAdminDefaults(v)
+	setMetricsDefaults(v)
+	setEventsDefaults(v)
+	setProjectionsDefaults(v)
+	setSagasDefaults(v)
 }
 
 func setAppDefaults(v *viper.Viper) {
@@ -167,6 +288,9 @@ func setDatabaseDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_open_conns", 25)
 	v.SetDefault("database.max_idle_conns", 5)
 	v.SetDefault("database.conn_max_lifetime", "5m")
+	v.SetDefault("database.order_store", "state")
+	v.SetDefault("database.snapshots.enabled", true)
+	v.SetDefault("database.snapshots.frequency.order", 50)
 	v.SetDefault("database.retry.enabled", true)
 	v.SetDefault("database.retry.max_attempts", 3)
 	v.SetDefault("database.retry.initial_delay", "100ms")
@@ -183,6 +307,37 @@ func setWorkerDefaults(v *viper.Viper) {
 	v.SetDefault("worker.poll_interval", "3s")
 	v.SetDefault("worker.batch_size", 100)
 	v.SetDefault("worker.max_retries", 5)
+	v.SetDefault("worker.worker_id", "")
+	v.SetDefault("worker.lease_duration", "30s")
+	v.SetDefault("worker.reaper_interval", "30s")
+	v.SetDefault("worker.ordering_mode", "none")
+	v.SetDefault("worker.concurrency", 1)
+	v.SetDefault("worker.drain_timeout", "30s")
+	v.SetDefault("worker.retry_backoff.initial_delay", "5s")
+	v.SetDefault("worker.retry_backoff.max_delay", "10m")
+	v.SetDefault("worker.retry_backoff.backoff_factor", 2.0)
+	v.SetDefault("worker.retry_backoff.jitter_enabled", true)
+	v.SetDefault("worker.retention.enabled", false)
+	v.SetDefault("worker.retention.retain_days", 7)
+	v.SetDefault("worker.retention.interval", "1h")
+	v.SetDefault("worker.retention.batch_size", 500)
+	v.SetDefault("worker.retention.batch_pause", "200ms")
+	v.SetDefault("worker.retention.archive", "table")
+	v.SetDefault("worker.retention.archive_dir", "archive/outbox")
+	v.SetDefault("worker.publisher", "logging")
+	v.SetDefault("worker.webhook.secret", "")
+	v.SetDefault("worker.webhook.timeout", "5s")
+	v.SetDefault("worker.webhook.envelope", "none")
+	v.SetDefault("worker.webhook.source", "")
+	v.SetDefault("worker.nats.url", "nats://127.0.0.1:4222")
+	v.SetDefault("worker.nats.subject_prefix", "outbox.")
+	v.SetDefault("worker.nats.ack_timeout", "5s")
+	v.SetDefault("worker.nats.envelope", "none")
+	v.SetDefault("worker.nats.source", "")
+	v.SetDefault("worker.embedded", false)
+	v.SetDefault("worker.leader_election.lock_name", "ddd:outbox-worker")
+	v.SetDefault("worker.leader_election.retry_interval", "5s")
+	v.SetDefault("worker.leader_election.check_interval", "5s")
 }
 
 func setLogDefaults(v *viper.Viper) {
@@ -199,3 +354,42 @@ func setCORSDefaults(v *viper.Viper) {
 	v.SetDefault("cors.allow_credentials", true)
 	v.SetDefault("cors.max_age", 86400)
 }
+
+func setAdminDefaults(v *viper.Viper) {
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.token", "")
+}
+
+func setMetricsDefaults(v *viper.Viper) {
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.worker_listen_addr", "")
+}
+
+func setEventsDefaults(v *viper.Viper) {
+	v.SetDefault("events.async", true)
+	v.SetDefault("events.queue_size", 256)
+	v.SetDefault("events.workers", 1)
+	v.SetDefault("events.retry", 0)
+	v.SetDefault("events.timeout", "0s")
+	v.SetDefault("events.drain_timeout", "10s")
+	v.SetDefault("events.tracing", true)
+}
+
+func setProjectionsDefaults(v *viper.Viper) {
+	v.SetDefault("projections.enabled", false)
+	v.SetDefault("projections.source", "outbox")
+	v.SetDefault("projections.batch_size", 200)
+	v.SetDefault("projections.poll_interval", "2s")
+	v.SetDefault("projections.settle_delay", "5s")
+}
+
+func setSagasDefaults(v *viper.Viper) {
+	v.SetDefault("sagas.enabled", false)
+	v.SetDefault("sagas.poll_interval", "1s")
+	v.SetDefault("sagas.batch_size", 20)
+	v.SetDefault("sagas.max_start_age", "1h")
+	v.SetDefault("sagas.compensation_max_attempts", 10)
+	v.SetDefault("sagas.order_fulfilment.step_timeout", "10s")
+	v.SetDefault("sagas.order_fulfilment.max_attempts", 3)
+	v.SetDefault("sagas.order_fulfilment.payment_limit", 0)
+}
@@ -29,6 +29,7 @@ type Order struct {
 	addedItems   []OrderItem
 	removedItems []OrderItem
 	isNew        bool
+	storedEvents int
 }
 
 type OrderItem struct {
@@ -124,6 +125,9 @@ func NewOrder(userID string, requests []ItemRequest) (*Order, error) {
 		isNew:        true,
 	}
 	o.events = append(o.events, NewOrderPlacedEvent(o.id, userID, o.totalAmount))
+	for _, item := range items {
+		o.events = append(o.events, NewOrderItemAddedEvent(o.id, item))
+	}
 	return o, nil
 }
 
@@ -220,6 +224,7 @@ func (o *Order) AddItem(productID, productName string, quantity int, unitPrice s
 	}
 	o.totalAmount = *newTotal
 	o.updatedAt = time.Now()
+	o.events = append(o.events, NewOrderItemAddedEvent(o.id, item))
 	return nil
 }
 
@@ -266,6 +271,7 @@ func (o *Order) RemoveItem(itemID string) error {
 	}
 	o.totalAmount = *newTotal
 	o.updatedAt = time.Now()
+	o.events = append(o.events, NewOrderItemRemovedEvent(o.id, itemID))
 	return nil
 }
 
@@ -289,6 +295,21 @@ func (o *Order) Cancel(reason string) error {
 	return nil
 }
 
+// Remove 供仓储的 Remove 撤销订单：除已签收的订单也可撤销外与 Cancel 相同，产生同样的取消事件。
+// 订单已取消时返回 false，不产生事件。
+func (o *Order) Remove(reason string) bool {
+	if o.status == StatusCancelled {
+		return false
+	}
+	if o.status != StatusDelivered {
+		return o.Cancel(reason) == nil
+	}
+	o.status = StatusCancelled
+	o.updatedAt = time.Now()
+	o.events = append(o.events, NewOrderCancelledEvent(o.id, reason))
+	return true
+}
+
 func (o *Order) Ship() error {
 	if o.status != StatusConfirmed {
 		return ErrInvalidOrderStateTransition
@@ -354,6 +375,7 @@ func (o *Order) PullEvents() []shared.DomainEvent {
 	events := make([]shared.DomainEvent, len(o.events))
 	copy(events, o.events)
 	o.events = make([]shared.DomainEvent, 0)
+	o.storedEvents = 0
 	return events
 }
 
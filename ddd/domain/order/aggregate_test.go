@@ -0,0 +1,40 @@
+package order
+
+import (
+	"testing"
+
+	"ddd/domain/shared"
+)
+
+func TestRemoveCancelsDeliveredOrder(t *testing.T) {
+	o, err := NewOrder("user-1", []ItemRequest{
+		{ProductID: "p-1", ProductName: "Keyboard", Quantity: 1, UnitPrice: *shared.NewMoney(500, "CNY")},
+	})
+	if err != nil {
+		t.Fatalf("NewOrder() error = %v", err)
+	}
+	for _, step := range []func() error{o.Confirm, o.Ship, o.Deliver} {
+		if err := step(); err != nil {
+			t.Fatalf("transition error = %v", err)
+		}
+	}
+	if err := o.Cancel("late"); err == nil {
+		t.Fatal("Cancel() on a delivered order succeeded, want ErrInvalidOrderStateTransition")
+	}
+	o.PullEvents()
+
+	if !o.Remove("removed") {
+		t.Fatal("Remove() = false for a delivered order")
+	}
+	events := o.PullEvents()
+	if o.Status() != StatusCancelled || len(events) != 1 {
+		t.Fatalf("after Remove() status = %s with %d events, want CANCELLED with 1 event", o.Status(), len(events))
+	}
+	if cancelled, ok := events[0].(*OrderCancelledEvent); !ok || cancelled.Reason() != "removed" {
+		t.Errorf("event = %#v, want order.cancelled with reason removed", events[0])
+	}
+
+	if o.Remove("removed") || len(o.PullEvents()) != 0 {
+		t.Error("Remove() on a cancelled order changed it again")
+	}
+}
@@ -20,6 +20,7 @@ var (
 	ErrItemNotFound                = errors.New("item not found")
 	ErrInvalidOrderStateTransition = errors.New("invalid order state transition")
 	ErrUserNotActiveForOrder       = errors.New("user is not active")
+	ErrCorruptedEventStream        = errors.New("order event stream is inconsistent")
 )
 
 func NewOrderNotFoundError(orderID string) error {
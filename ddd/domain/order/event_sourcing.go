@@ -0,0 +1,119 @@
+package order
+
+import (
+	"fmt"
+
+	"ddd/domain/shared"
+)
+
+// RebuildFromEvents 按顺序重放订单的完整事件流重建聚合，仅供事件溯源仓储调用。
+// 聚合版本等于已重放的事件数，即事件流的当前版本。
+func RebuildFromEvents(events []shared.DomainEvent) (*Order, error) {
+	if len(events) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	o := &Order{}
+	if err := o.Replay(events); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Replay 在当前状态上依次应用已持久化的事件，每个事件使版本加一。
+// 重放不做业务校验，也不产生新的待发布事件；仅供仓储层使用。
+func (o *Order) Replay(events []shared.DomainEvent) error {
+	for _, event := range events {
+		if err := o.apply(event); err != nil {
+			return err
+		}
+		o.version++
+		o.updatedAt = event.OccurredOn()
+	}
+	return nil
+}
+
+func (o *Order) apply(event shared.DomainEvent) error {
+	if placed, ok := event.(*OrderPlacedEvent); ok {
+		if o.id != "" {
+			return fmt.Errorf("%w: order %s placed twice", ErrCorruptedEventStream, o.id)
+		}
+		o.id = placed.OrderID()
+		o.userID = placed.UserID()
+		o.status = StatusPending
+		o.totalAmount = *shared.NewMoney(0, placed.TotalAmount().Currency())
+		o.createdAt = placed.OccurredOn()
+		return nil
+	}
+
+	if o.id == "" || event.GetAggregateID() != o.id {
+		return fmt.Errorf("%w: %s for %s does not follow order.placed of %q",
+			ErrCorruptedEventStream, event.EventName(), event.GetAggregateID(), o.id)
+	}
+
+	switch e := event.(type) {
+	case *OrderItemAddedEvent:
+		o.items = append(o.items, e.Item())
+		return o.recalculateTotal()
+	case *OrderItemRemovedEvent:
+		for i, item := range o.items {
+			if item.id == e.ItemID() {
+				o.items = append(o.items[:i:i], o.items[i+1:]...)
+				return o.recalculateTotal()
+			}
+		}
+		return fmt.Errorf("%w: removed item %s does not exist", ErrCorruptedEventStream, e.ItemID())
+	case *OrderConfirmedEvent:
+		o.status = StatusConfirmed
+	case *OrderShippedEvent:
+		o.status = StatusShipped
+	case *OrderDeliveredEvent:
+		o.status = StatusDelivered
+	case *OrderCancelledEvent:
+		o.status = StatusCancelled
+	default:
+		return fmt.Errorf("%w: unsupported event %s", ErrCorruptedEventStream, event.EventName())
+	}
+	return nil
+}
+
+func (o *Order) recalculateTotal() error {
+	total := shared.NewMoney(0, o.totalAmount.Currency())
+	for _, item := range o.items {
+		var err error
+		total, err = total.Add(item.subtotal)
+		if err != nil {
+			return err
+		}
+	}
+	o.totalAmount = *total
+	return nil
+}
+
+// Snapshot 导出当前状态供快照存储序列化，Version 为快照对应的事件流版本。
+// 以 RebuildFromDTO 还原后再 Replay 更新的事件，即可从快照继续重建；仅供仓储层使用。
+func (o *Order) Snapshot() ReconstructionDTO {
+	return ReconstructionDTO{
+		ID:          o.id,
+		UserID:      o.userID,
+		Items:       o.Items(),
+		TotalAmount: o.totalAmount,
+		Status:      o.status,
+		Version:     o.version,
+		CreatedAt:   o.createdAt,
+		UpdatedAt:   o.updatedAt,
+	}
+}
+
+// UnstoredEvents 返回尚未追加到事件流的事件，仅供事件溯源仓储使用。
+// 与 PullEvents 互不影响：同一批事件仍由工作单元写入 outbox。
+func (o *Order) UnstoredEvents() []shared.DomainEvent {
+	events := make([]shared.DomainEvent, len(o.events)-o.storedEvents)
+	copy(events, o.events[o.storedEvents:])
+	return events
+}
+
+// MarkEventsStored 记录待发布事件已全部追加到事件流，version 为追加后的流版本。
+func (o *Order) MarkEventsStored(version int) {
+	o.storedEvents = len(o.events)
+	o.version = version
+}
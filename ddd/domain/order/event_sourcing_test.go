@@ -0,0 +1,61 @@
+package order
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ddd/domain/shared"
+)
+
+func TestRebuildFromEventsMatchesLiveAggregate(t *testing.T) {
+	o, err := NewOrder("user-1", []ItemRequest{
+		{ProductID: "p-1", ProductName: "Keyboard", Quantity: 2, UnitPrice: *shared.NewMoney(500, "CNY")},
+		{ProductID: "p-2", ProductName: "Mouse", Quantity: 1, UnitPrice: *shared.NewMoney(200, "CNY")},
+	})
+	if err != nil {
+		t.Fatalf("NewOrder() error = %v", err)
+	}
+	if err := o.AddItem("p-3", "Monitor", 1, *shared.NewMoney(3000, "CNY")); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if err := o.RemoveItem(o.Items()[1].ID()); err != nil {
+		t.Fatalf("RemoveItem() error = %v", err)
+	}
+	if err := o.Confirm(); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+
+	events := o.UnstoredEvents()
+	o.MarkEventsStored(len(events))
+	if len(o.UnstoredEvents()) != 0 {
+		t.Fatal("UnstoredEvents() not empty after MarkEventsStored")
+	}
+
+	rebuilt, err := RebuildFromEvents(events)
+	if err != nil {
+		t.Fatalf("RebuildFromEvents() error = %v", err)
+	}
+	if rebuilt.ID() != o.ID() || rebuilt.UserID() != o.UserID() || rebuilt.Status() != StatusConfirmed {
+		t.Errorf("rebuilt = %s/%s/%s, want %s/%s/%s", rebuilt.ID(), rebuilt.UserID(), rebuilt.Status(), o.ID(), o.UserID(), StatusConfirmed)
+	}
+	if !reflect.DeepEqual(rebuilt.Items(), o.Items()) {
+		t.Errorf("rebuilt items = %v, want %v", rebuilt.Items(), o.Items())
+	}
+	if !rebuilt.TotalAmount().Equals(o.TotalAmount()) || rebuilt.TotalAmount().Amount() != 4000 {
+		t.Errorf("rebuilt total = %s, want %s", rebuilt.TotalAmount(), o.TotalAmount())
+	}
+	if rebuilt.Version() != len(events) {
+		t.Errorf("rebuilt version = %d, want %d", rebuilt.Version(), len(events))
+	}
+	if len(rebuilt.PullEvents()) != 0 {
+		t.Error("replay must not produce pending events")
+	}
+}
+
+func TestRebuildFromEventsRejectsInconsistentStream(t *testing.T) {
+	_, err := RebuildFromEvents([]shared.DomainEvent{NewOrderConfirmedEvent("order-1")})
+	if !errors.Is(err, ErrCorruptedEventStream) {
+		t.Errorf("RebuildFromEvents() error = %v, want ErrCorruptedEventStream", err)
+	}
+}
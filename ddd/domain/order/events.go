@@ -22,6 +22,16 @@ func NewOrderPlacedEvent(orderID, userID string, totalAmount shared.Money) *Orde
 	}
 }
 
+// RebuildOrderPlacedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderPlacedEvent(orderID, userID string, totalAmount shared.Money, occurredOn time.Time) *OrderPlacedEvent {
+	return &OrderPlacedEvent{
+		orderID:     orderID,
+		userID:      userID,
+		totalAmount: totalAmount,
+		occurredOn:  occurredOn,
+	}
+}
+
 func (e *OrderPlacedEvent) EventName() string         { return "order.placed" }
 func (e *OrderPlacedEvent) OccurredOn() time.Time     { return e.occurredOn }
 func (e *OrderPlacedEvent) GetAggregateID() string    { return e.orderID }
@@ -41,6 +51,14 @@ func NewOrderConfirmedEvent(orderID string) *OrderConfirmedEvent {
 	}
 }
 
+// RebuildOrderConfirmedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderConfirmedEvent(orderID string, occurredOn time.Time) *OrderConfirmedEvent {
+	return &OrderConfirmedEvent{
+		orderID:    orderID,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *OrderConfirmedEvent) EventName() string      { return "order.confirmed" }
 func (e *OrderConfirmedEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *OrderConfirmedEvent) GetAggregateID() string { return e.orderID }
@@ -58,6 +76,14 @@ func NewOrderShippedEvent(orderID string) *OrderShippedEvent {
 	}
 }
 
+// RebuildOrderShippedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderShippedEvent(orderID string, occurredOn time.Time) *OrderShippedEvent {
+	return &OrderShippedEvent{
+		orderID:    orderID,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *OrderShippedEvent) EventName() string      { return "order.shipped" }
 func (e *OrderShippedEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *OrderShippedEvent) GetAggregateID() string { return e.orderID }
@@ -75,6 +101,14 @@ func NewOrderDeliveredEvent(orderID string) *OrderDeliveredEvent {
 	}
 }
 
+// RebuildOrderDeliveredEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderDeliveredEvent(orderID string, occurredOn time.Time) *OrderDeliveredEvent {
+	return &OrderDeliveredEvent{
+		orderID:    orderID,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *OrderDeliveredEvent) EventName() string      { return "order.delivered" }
 func (e *OrderDeliveredEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *OrderDeliveredEvent) GetAggregateID() string { return e.orderID }
@@ -94,8 +128,76 @@ func NewOrderCancelledEvent(orderID, reason string) *OrderCancelledEvent {
 	}
 }
 
+// RebuildOrderCancelledEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderCancelledEvent(orderID, reason string, occurredOn time.Time) *OrderCancelledEvent {
+	return &OrderCancelledEvent{
+		orderID:    orderID,
+		reason:     reason,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *OrderCancelledEvent) EventName() string      { return "order.cancelled" }
 func (e *OrderCancelledEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *OrderCancelledEvent) GetAggregateID() string { return e.orderID }
 func (e *OrderCancelledEvent) OrderID() string        { return e.orderID }
 func (e *OrderCancelledEvent) Reason() string         { return e.reason }
+
+// OrderItemAddedEvent 携带完整的订单项数据，事件溯源重放时据此还原订单项。
+type OrderItemAddedEvent struct {
+	orderID    string
+	item       OrderItem
+	occurredOn time.Time
+}
+
+func NewOrderItemAddedEvent(orderID string, item OrderItem) *OrderItemAddedEvent {
+	return &OrderItemAddedEvent{
+		orderID:    orderID,
+		item:       item,
+		occurredOn: time.Now(),
+	}
+}
+
+// RebuildOrderItemAddedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderItemAddedEvent(orderID string, item OrderItem, occurredOn time.Time) *OrderItemAddedEvent {
+	return &OrderItemAddedEvent{
+		orderID:    orderID,
+		item:       item,
+		occurredOn: occurredOn,
+	}
+}
+
+func (e *OrderItemAddedEvent) EventName() string      { return "order.item_added" }
+func (e *OrderItemAddedEvent) OccurredOn() time.Time  { return e.occurredOn }
+func (e *OrderItemAddedEvent) GetAggregateID() string { return e.orderID }
+func (e *OrderItemAddedEvent) OrderID() string        { return e.orderID }
+func (e *OrderItemAddedEvent) Item() OrderItem        { return e.item }
+
+type OrderItemRemovedEvent struct {
+	orderID    string
+	itemID     string
+	occurredOn time.Time
+}
+
+func NewOrderItemRemovedEvent(orderID, itemID string) *OrderItemRemovedEvent {
+	return &OrderItemRemovedEvent{
+		orderID:    orderID,
+		itemID:     itemID,
+		occurredOn: time.Now(),
+	}
+}
+
+// RebuildOrderItemRemovedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildOrderItemRemovedEvent(orderID, itemID string, occurredOn time.Time) *OrderItemRemovedEvent {
+	return &OrderItemRemovedEvent{
+		orderID:    orderID,
+		itemID:     itemID,
+		occurredOn: occurredOn,
+	}
+}
+
+func (e *OrderItemRemovedEvent) EventName() string      { return "order.item_removed" }
+func (e *OrderItemRemovedEvent) OccurredOn() time.Time  { return e.occurredOn }
+func (e *OrderItemRemovedEvent) GetAggregateID() string { return e.orderID }
+func (e *OrderItemRemovedEvent) OrderID() string        { return e.orderID }
+func (e *OrderItemRemovedEvent) ItemID() string         { return e.itemID }
@@ -0,0 +1,26 @@
+package order
+
+import (
+	"context"
+	"time"
+)
+
+// UserSpending 是用户消费汇总读模型，由投影根据订单事件维护，可能略滞后于写模型。
+type UserSpending struct {
+	UserID          string
+	Currency        string
+	OrderCount      int64
+	DeliveredOrders int64
+	TotalSpent      int64
+	UpdatedAt       time.Time
+}
+
+// UserSpendingReader 读取用户消费汇总，用户没有任何订单时返回空切片。
+type UserSpendingReader interface {
+	FindUserSpending(ctx context.Context, userID string) ([]*UserSpending, error)
+}
+
+// HistoryReader 还原订单在某一时刻的状态，订单在该时刻尚不存在或没有历史记录时返回 ErrOrderNotFound。
+type HistoryReader interface {
+	FindAsOf(ctx context.Context, orderID string, at time.Time) (*Order, error)
+}
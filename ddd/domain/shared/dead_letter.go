@@ -0,0 +1,44 @@
+package shared
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetterEvent 表示超过最大重试次数后停留在 FAILED 状态的 outbox 事件。
+type DeadLetterEvent struct {
+	ID                string
+	AggregateID       string
+	AggregateSequence int64
+	EventType         string
+	EventVersion      int
+	Payload           string
+	RetryCount        int
+	LastError         string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// DeadLetterFilter 描述死信查询条件，空字段表示不过滤。
+type DeadLetterFilter struct {
+	EventType   string
+	AggregateID string
+	Limit       int
+	Offset      int
+}
+
+// DeadLetterAudit 记录人工处置死信时的操作人与说明。
+type DeadLetterAudit struct {
+	Operator string
+	Note     string
+}
+
+// DeadLetterRepository 管理 FAILED 状态的 outbox 事件。
+type DeadLetterRepository interface {
+	ListFailedEvents(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetterEvent, int64, error)
+	GetFailedEvent(ctx context.Context, eventID string) (*DeadLetterEvent, error)
+	// ReplayFailedEvents 将事件重置为 PENDING 并清零重试次数，返回实际重放的数量。
+	ReplayFailedEvents(ctx context.Context, eventIDs []string, audit DeadLetterAudit) (int64, error)
+	// DiscardFailedEvents 将事件标记为 DISCARDED，不再投递，返回实际丢弃的数量。
+	DiscardFailedEvents(ctx context.Context, eventIDs []string, audit DeadLetterAudit) (int64, error)
+}
@@ -1,9 +1,13 @@
 package shared
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type DomainEvent interface {
@@ -13,23 +17,88 @@ type DomainEvent interface {
 }
 type DomainEventPublisher interface {
 	Publish(event DomainEvent) error
-	Subscribe(eventName string, handler EventHandler) error
-	Unsubscribe(eventName string, handler EventHandler) error
+	Subscribe(eventName string, handler EventHandler) (*EventSubscription, error)
+	Unsubscribe(subscriptionID string) error
+}
+
+// ContextEventPublisher 是 DomainEventPublisher 的可选扩展，ctx 会传给处理器与中间件。
+type ContextEventPublisher interface {
+	DomainEventPublisher
+	PublishContext(ctx context.Context, event DomainEvent) error
 }
 type EventHandler interface {
 	Handle(event DomainEvent) error
 	Name() string
 }
+
+// ContextEventHandler 是 EventHandler 的可选扩展，调度方会传入携带事务的 ctx，
+// 处理器经该 ctx 调用仓储即可与调度方的记录在同一事务内提交。
+type ContextEventHandler interface {
+	EventHandler
+	HandleContext(ctx context.Context, event DomainEvent) error
+}
+
+// EventPublishOptions 控制单个订阅的处理方式。
 type EventPublishOptions struct {
-	Retry   int
+	// Retry 为首次失败后的重试次数。
+	Retry int
+	// Timeout 为单次处理的超时，为 0 时不限制。
 	Timeout time.Duration
+	// Middlewares 只作用于该订阅，位于 EventBusOptions.Middlewares 之内。
+	Middlewares []EventMiddleware
 }
+
+// EventSubscription 是订阅的句柄，IsActive 为创建句柄或最近一次 Pause/Resume 时的状态。
 type EventSubscription struct {
-	ID        string       `json:"id"`
-	EventName string       `json:"event_name"`
-	Handler   EventHandler `json:"-"`
-	CreatedAt time.Time    `json:"created_at"`
-	IsActive  bool         `json:"is_active"`
+	ID          string       `json:"id"`
+	EventName   string       `json:"event_name"`
+	Handler     EventHandler `json:"-"`
+	HandlerName string       `json:"handler"`
+	CreatedAt   time.Time    `json:"created_at"`
+	IsActive    bool         `json:"is_active"`
+
+	registry subscriptionRegistry
+}
+
+type subscriptionRegistry interface {
+	Unsubscribe(subscriptionID string) error
+	SetSubscriptionActive(subscriptionID string, active bool) error
+}
+
+// Pause 暂停投递，暂停期间发布的事件不会交给该处理器，也不会在恢复后补发。
+func (s *EventSubscription) Pause() error {
+	return s.setActive(false)
+}
+
+// Resume 恢复投递。
+func (s *EventSubscription) Resume() error {
+	return s.setActive(true)
+}
+
+// Unsubscribe 移除该订阅。
+func (s *EventSubscription) Unsubscribe() error {
+	if s.registry == nil {
+		return fmt.Errorf("subscription %s is not attached to a publisher", s.ID)
+	}
+	return s.registry.Unsubscribe(s.ID)
+}
+
+func (s *EventSubscription) setActive(active bool) error {
+	if s.registry == nil {
+		return fmt.Errorf("subscription %s is not attached to a publisher", s.ID)
+	}
+	if err := s.registry.SetSubscriptionActive(s.ID, active); err != nil {
+		return err
+	}
+	s.IsActive = active
+	return nil
+}
+
+// SubscriptionStatus 是订阅及其最近处理结果的快照，供运维接口展示。
+type SubscriptionStatus struct {
+	EventSubscription
+	Filtered bool                 `json:"filtered"`
+	History  []EventPublishResult `json:"history"`
 }
 type EventPublishResult struct {
 	EventName   string    `json:"event_name"`
@@ -60,26 +129,80 @@ func ValidateEvent(event DomainEvent) error {
 	return nil
 }
 
+// EventBusOptions 配置 EventBus 的分发方式，零值为同步分发。
+type EventBusOptions struct {
+	// Async 为 true 时 Publish 只把事件放入各订阅的队列即返回，由订阅自己的 worker 调用处理器。
+	Async bool
+	// QueueSize 为每个订阅的队列容量，队列已满时 Publish 对该订阅返回 ErrEventQueueFull。
+	QueueSize int
+	// Workers 为每个订阅消费队列的 goroutine 数，为 1 时同一订阅按发布顺序处理。
+	Workers int
+	// DefaultOptions 应用于未单独指定选项的订阅。
+	DefaultOptions EventPublishOptions
+	// Middlewares 作用于所有订阅，按顺序由外到内包装处理器。
+	Middlewares []EventMiddleware
+	// OnError 接收异步处理在重试耗尽后仍失败的事件，可用于记录日志或转入死信。
+	OnError func(event DomainEvent, handler string, err error)
+}
+
+const (
+	DefaultEventQueueSize = 256
+	maxPublishHistory     = 1000
+)
+
 type EventBus struct {
-	handlers  map[string][]EventHandler
-	mu        sync.RWMutex
-	history   []EventPublishResult
-	muHistory sync.Mutex
+	// subscriptions 按订阅顺序保存，同一事件的处理器总是按订阅先后执行。
+	subscriptions []*subscription
+	mu            sync.RWMutex
+	history       []EventPublishResult
+	muHistory     sync.Mutex
+	options       EventBusOptions
+	closed        bool
+	workers       sync.WaitGroup
 }
 
+// NewEventBus 创建同步分发的事件总线，Publish 在调用方 goroutine 内依次执行处理器。
 func NewEventBus() *EventBus {
+	return NewEventBusWithOptions(EventBusOptions{})
+}
+
+func NewEventBusWithOptions(options EventBusOptions) *EventBus {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultEventQueueSize
+	}
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
 	return &EventBus{
-		handlers: make(map[string][]EventHandler),
-		history:  make([]EventPublishResult, 0),
+		history: make([]EventPublishResult, 0),
+		options: options,
 	}
 }
+
+// Publish 同步模式下返回所有处理器的错误；异步模式下只返回入队失败的错误，
+// 处理失败记录在发布历史中并交给 OnError。
 func (bus *EventBus) Publish(event DomainEvent) error {
+	return bus.PublishContext(context.Background(), event)
+}
+
+// PublishContext 与 Publish 相同，ctx 会传给中间件与处理器；异步模式下不继承 ctx 的取消。
+func (bus *EventBus) PublishContext(ctx context.Context, event DomainEvent) error {
 	if err := ValidateEvent(event); err != nil {
 		return err
 	}
 
 	bus.mu.RLock()
-	handlers, exists := bus.handlers[event.EventName()]
+	if bus.closed {
+		bus.mu.RUnlock()
+		return ErrEventBusClosed
+	}
+	subs := bus.matching(event)
+	if bus.options.Async {
+		// 入队不阻塞，持有读锁可避免与 Unsubscribe/Close 关闭队列并发。
+		err := bus.enqueue(context.WithoutCancel(ctx), event, subs)
+		bus.mu.RUnlock()
+		return err
+	}
 	bus.mu.RUnlock()
 
 	result := EventPublishResult{
@@ -88,76 +211,242 @@ func (bus *EventBus) Publish(event DomainEvent) error {
 		PublishedAt: time.Now(),
 	}
 
-	if exists && len(handlers) > 0 {
+	if len(subs) > 0 {
 		var errs []error
-		for _, handler := range handlers {
-			if err := handler.Handle(event); err != nil {
-				errs = append(errs, fmt.Errorf("handler %s: %w", handler.Name(), err))
+		for _, sub := range subs {
+			if err := sub.handle(ctx, event); err != nil {
+				errs = append(errs, fmt.Errorf("handler %s: %w", sub.handler.Name(), err))
 			}
 		}
 		if len(errs) > 0 {
 			result.Success = false
 			result.Message = fmt.Sprintf("%d handlers failed", len(errs))
-			bus.muHistory.Lock()
-			bus.history = append(bus.history, result)
-			if len(bus.history) > 1000 {
-				bus.history = bus.history[len(bus.history)-1000:]
-			}
-			bus.muHistory.Unlock()
-			return fmt.Errorf("event %s: %d handlers failed: %v", event.EventName(), len(errs), errs)
+			bus.record(result)
+			return fmt.Errorf("event %s: %d handlers failed: %w", event.EventName(), len(errs), errors.Join(errs...))
 		}
 	} else {
 		result.Message = "no handlers registered for this event"
 	}
 
-	bus.muHistory.Lock()
-	bus.history = append(bus.history, result)
-	if len(bus.history) > 1000 {
-		bus.history = bus.history[len(bus.history)-1000:]
+	bus.record(result)
+	return nil
+}
+
+// matching 返回匹配事件的订阅，调用方需持有读锁。
+func (bus *EventBus) matching(event DomainEvent) []*subscription {
+	var subs []*subscription
+	for _, sub := range bus.subscriptions {
+		if sub.matches(event) {
+			subs = append(subs, sub)
+		}
 	}
-	bus.muHistory.Unlock()
+	return subs
+}
 
+func (bus *EventBus) enqueue(ctx context.Context, event DomainEvent, subs []*subscription) error {
+	result := EventPublishResult{
+		EventName:   event.EventName(),
+		Success:     true,
+		PublishedAt: time.Now(),
+	}
+	if len(subs) == 0 {
+		result.Message = "no handlers registered for this event"
+		bus.record(result)
+		return nil
+	}
+
+	var rejected []string
+	for _, sub := range subs {
+		select {
+		case sub.queue <- queuedEvent{ctx: ctx, event: event}:
+		default:
+			rejected = append(rejected, sub.handler.Name())
+		}
+	}
+	if len(rejected) > 0 {
+		result.Success = false
+		result.Message = fmt.Sprintf("queue full for handlers %v", rejected)
+		bus.record(result)
+		return fmt.Errorf("event %s: %w for handlers %v", event.EventName(), ErrEventQueueFull, rejected)
+	}
+
+	result.Message = fmt.Sprintf("queued for %d handlers", len(subs))
+	bus.record(result)
 	return nil
 }
-func (bus *EventBus) Subscribe(eventName string, handler EventHandler) error {
-	if eventName == "" {
-		return fmt.Errorf("event name cannot be empty")
+
+// Subscribe 订阅事件，eventName 支持精确名称、前缀通配（如 "order.*"）以及 "*"。
+func (bus *EventBus) Subscribe(eventName string, handler EventHandler) (*EventSubscription, error) {
+	return bus.subscribe(eventName, handler, bus.options.DefaultOptions, nil)
+}
+
+// SubscribeWithOptions 以单独的重试次数与超时订阅事件。
+func (bus *EventBus) SubscribeWithOptions(eventName string, handler EventHandler, options EventPublishOptions) (*EventSubscription, error) {
+	return bus.subscribe(eventName, handler, options, nil)
+}
+
+// SubscribeFiltered 订阅匹配 eventName 且满足 filter 的事件，例如只处理某个聚合的事件。
+func (bus *EventBus) SubscribeFiltered(eventName string, filter EventFilter, handler EventHandler) (*EventSubscription, error) {
+	if filter == nil {
+		return nil, fmt.Errorf("filter cannot be nil")
+	}
+	return bus.subscribe(eventName, handler, bus.options.DefaultOptions, filter)
+}
+
+func (bus *EventBus) subscribe(eventName string, handler EventHandler, options EventPublishOptions, filter EventFilter) (*EventSubscription, error) {
+	if err := validateEventPattern(eventName); err != nil {
+		return nil, err
 	}
 
 	if handler == nil {
-		return fmt.Errorf("handler cannot be nil")
+		return nil, fmt.Errorf("handler cannot be nil")
 	}
 
 	bus.mu.Lock()
 	defer bus.mu.Unlock()
 
-	for _, h := range bus.handlers[eventName] {
-		if h.Name() == handler.Name() {
-			return fmt.Errorf("handler %s already subscribed to %s", handler.Name(), eventName)
+	if bus.closed {
+		return nil, ErrEventBusClosed
+	}
+	for _, sub := range bus.subscriptions {
+		if sub.pattern == eventName && sub.handler.Name() == handler.Name() {
+			return nil, fmt.Errorf("handler %s already subscribed to %s", handler.Name(), eventName)
 		}
 	}
 
-	bus.handlers[eventName] = append(bus.handlers[eventName], handler)
-	return nil
+	sub := &subscription{
+		id:        uuid.NewString(),
+		createdAt: time.Now(),
+		pattern:   eventName,
+		filter:    filter,
+		handler:   handler,
+		options:   options,
+		pipeline:  buildPipeline(handler, options, bus.options.Middlewares),
+	}
+	sub.active.Store(true)
+	if bus.options.Async {
+		sub.queue = make(chan queuedEvent, bus.options.QueueSize)
+		bus.workers.Add(bus.options.Workers)
+		for i := 0; i < bus.options.Workers; i++ {
+			go bus.consume(sub)
+		}
+	}
+	bus.subscriptions = append(bus.subscriptions, sub)
+	return sub.snapshot(bus), nil
 }
-func (bus *EventBus) Unsubscribe(eventName string, handler EventHandler) error {
+
+// Unsubscribe 按 ID 移除订阅，异步模式下已入队的事件仍会处理完。
+func (bus *EventBus) Unsubscribe(subscriptionID string) error {
 	bus.mu.Lock()
 	defer bus.mu.Unlock()
 
-	handlers, exists := bus.handlers[eventName]
-	if !exists {
-		return nil
+	for i, sub := range bus.subscriptions {
+		if sub.id == subscriptionID {
+			bus.subscriptions = append(bus.subscriptions[:i:i], bus.subscriptions[i+1:]...)
+			sub.close()
+			return nil
+		}
 	}
 
-	for i, h := range handlers {
-		if h.Name() == handler.Name() {
-			bus.handlers[eventName] = append(handlers[:i], handlers[i+1:]...)
+	return fmt.Errorf("subscription %s: %w", subscriptionID, ErrNotFound)
+}
+
+// SetSubscriptionActive 暂停或恢复订阅，异步模式下已入队的事件不受影响。
+func (bus *EventBus) SetSubscriptionActive(subscriptionID string, active bool) error {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	for _, sub := range bus.subscriptions {
+		if sub.id == subscriptionID {
+			sub.active.Store(active)
 			return nil
 		}
 	}
+	return fmt.Errorf("subscription %s: %w", subscriptionID, ErrNotFound)
+}
 
-	return nil
+// Subscription 返回指定订阅的句柄。
+func (bus *EventBus) Subscription(subscriptionID string) (*EventSubscription, error) {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	for _, sub := range bus.subscriptions {
+		if sub.id == subscriptionID {
+			return sub.snapshot(bus), nil
+		}
+	}
+	return nil, fmt.Errorf("subscription %s: %w", subscriptionID, ErrNotFound)
+}
+
+// Subscriptions 按订阅顺序返回全部订阅及其最近的处理结果。
+func (bus *EventBus) Subscriptions() []SubscriptionStatus {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	statuses := make([]SubscriptionStatus, len(bus.subscriptions))
+	for i, sub := range bus.subscriptions {
+		statuses[i] = SubscriptionStatus{
+			EventSubscription: *sub.snapshot(bus),
+			Filtered:          sub.filter != nil,
+			History:           sub.recentResults(),
+		}
+	}
+	return statuses
+}
+
+// Close 拒绝新的发布与订阅，并等待异步队列中的事件处理完毕；ctx 结束时不再等待。
+func (bus *EventBus) Close(ctx context.Context) error {
+	bus.mu.Lock()
+	if !bus.closed {
+		bus.closed = true
+		for _, sub := range bus.subscriptions {
+			sub.close()
+		}
+	}
+	bus.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		bus.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event bus closed before draining: %w", ctx.Err())
+	}
 }
+
+func (bus *EventBus) consume(sub *subscription) {
+	defer bus.workers.Done()
+	for item := range sub.queue {
+		event := item.event
+		err := sub.handle(item.ctx, event)
+		if err == nil {
+			continue
+		}
+		bus.record(EventPublishResult{
+			EventName:   event.EventName(),
+			Success:     false,
+			Message:     fmt.Sprintf("handler %s: %v", sub.handler.Name(), err),
+			PublishedAt: time.Now(),
+		})
+		if bus.options.OnError != nil {
+			bus.options.OnError(event, sub.handler.Name(), err)
+		}
+	}
+}
+
+func (bus *EventBus) record(result EventPublishResult) {
+	bus.muHistory.Lock()
+	defer bus.muHistory.Unlock()
+	bus.history = append(bus.history, result)
+	if len(bus.history) > maxPublishHistory {
+		bus.history = bus.history[len(bus.history)-maxPublishHistory:]
+	}
+}
+
 func (bus *EventBus) GetPublishHistory() []EventPublishResult {
 	bus.muHistory.Lock()
 	defer bus.muHistory.Unlock()
@@ -167,6 +456,8 @@ func (bus *EventBus) GetPublishHistory() []EventPublishResult {
 	return history
 }
 
+var _ ContextEventPublisher = (*EventBus)(nil)
+
 type FuncHandler struct {
 	name string
 	fn   func(DomainEvent) error
@@ -189,14 +480,25 @@ func (h *FuncHandler) Name() string {
 }
 
 type MockEventPublisher struct {
-	handlers map[string][]EventHandler
-	mu       sync.RWMutex
+	subscriptions []*mockSubscription
+	mu            sync.RWMutex
 }
 
-func NewMockEventPublisher() *MockEventPublisher {
-	return &MockEventPublisher{
-		handlers: make(map[string][]EventHandler),
+// mockSubscription 与 EventBus 的订阅一致：事件名支持通配符，过滤器在名称匹配后生效。
+type mockSubscription struct {
+	*EventSubscription
+	filter EventFilter
+}
+
+func (s *mockSubscription) matches(event DomainEvent) bool {
+	if !s.IsActive || !MatchEventName(s.EventName, event.EventName()) {
+		return false
 	}
+	return s.filter == nil || s.filter(event)
+}
+
+func NewMockEventPublisher() *MockEventPublisher {
+	return &MockEventPublisher{}
 }
 
 func (p *MockEventPublisher) Publish(event DomainEvent) error {
@@ -205,14 +507,12 @@ func (p *MockEventPublisher) Publish(event DomainEvent) error {
 	}
 
 	p.mu.RLock()
-	handlers, exists := p.handlers[event.EventName()]
-	p.mu.RUnlock()
-
-	if exists {
-		for _, handler := range handlers {
-			go handler.Handle(event)
+	for _, sub := range p.subscriptions {
+		if sub.matches(event) {
+			go sub.Handler.Handle(event)
 		}
 	}
+	p.mu.RUnlock()
 
 	fmt.Printf("[EVENT PUBLISHED] %s at %s for aggregate %s\n",
 		event.EventName(),
@@ -222,31 +522,68 @@ func (p *MockEventPublisher) Publish(event DomainEvent) error {
 	return nil
 }
 
-func (p *MockEventPublisher) Subscribe(eventName string, handler EventHandler) error {
+func (p *MockEventPublisher) Subscribe(eventName string, handler EventHandler) (*EventSubscription, error) {
+	return p.subscribe(eventName, handler, nil)
+}
+
+// SubscribeFiltered 与 EventBus.SubscribeFiltered 相同，只处理满足 filter 的事件。
+func (p *MockEventPublisher) SubscribeFiltered(eventName string, filter EventFilter, handler EventHandler) (*EventSubscription, error) {
+	if filter == nil {
+		return nil, fmt.Errorf("filter cannot be nil")
+	}
+	return p.subscribe(eventName, handler, filter)
+}
+
+func (p *MockEventPublisher) subscribe(eventName string, handler EventHandler, filter EventFilter) (*EventSubscription, error) {
+	if err := validateEventPattern(eventName); err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	p.handlers[eventName] = append(p.handlers[eventName], handler)
-	return nil
+	sub := &EventSubscription{
+		ID:          uuid.NewString(),
+		EventName:   eventName,
+		Handler:     handler,
+		HandlerName: handler.Name(),
+		CreatedAt:   time.Now(),
+		IsActive:    true,
+	}
+	p.subscriptions = append(p.subscriptions, &mockSubscription{EventSubscription: sub, filter: filter})
+
+	handle := *sub
+	handle.registry = p
+	return &handle, nil
 }
 
-func (p *MockEventPublisher) Unsubscribe(eventName string, handler EventHandler) error {
+func (p *MockEventPublisher) Unsubscribe(subscriptionID string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	handlers, exists := p.handlers[eventName]
-	if !exists {
-		return nil
+	for i, sub := range p.subscriptions {
+		if sub.ID == subscriptionID {
+			p.subscriptions = append(p.subscriptions[:i:i], p.subscriptions[i+1:]...)
+			return nil
+		}
 	}
+	return fmt.Errorf("subscription %s: %w", subscriptionID, ErrNotFound)
+}
+
+func (p *MockEventPublisher) SetSubscriptionActive(subscriptionID string, active bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	for i, h := range handlers {
-		if h.Name() == handler.Name() {
-			p.handlers[eventName] = append(handlers[:i], handlers[i+1:]...)
+	for _, sub := range p.subscriptions {
+		if sub.ID == subscriptionID {
+			sub.IsActive = active
 			return nil
 		}
 	}
-
-	return nil
+	return fmt.Errorf("subscription %s: %w", subscriptionID, ErrNotFound)
 }
 
 type LoggingEventHandler struct{}
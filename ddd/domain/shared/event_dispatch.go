@@ -0,0 +1,168 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxSubscriptionHistory 为每个订阅保留的最近处理结果条数。
+const maxSubscriptionHistory = 20
+
+var (
+	ErrEventBusClosed  = errors.New("event bus is closed")
+	ErrEventQueueFull  = errors.New("event queue is full")
+	ErrHandlerTimeout  = errors.New("event handler timed out")
+	ErrHandlerPanicked = errors.New("event handler panicked")
+)
+
+// EventFilter 在事件名匹配之后按内容筛选事件，返回 false 的事件不会交给处理器。
+type EventFilter func(event DomainEvent) bool
+
+// ForAggregate 只接收指定聚合的事件。
+func ForAggregate(aggregateIDs ...string) EventFilter {
+	ids := make(map[string]struct{}, len(aggregateIDs))
+	for _, id := range aggregateIDs {
+		ids[id] = struct{}{}
+	}
+	return func(event DomainEvent) bool {
+		_, ok := ids[event.GetAggregateID()]
+		return ok
+	}
+}
+
+// MatchEventName 判断事件名是否匹配订阅模式："*" 匹配全部，"prefix*" 匹配前缀，其余为精确匹配。
+func MatchEventName(pattern, eventName string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(eventName, prefix)
+	}
+	return pattern == eventName
+}
+
+// validateEventPattern 只允许通配符出现在末尾，避免 "order.*.created" 这类看似支持实际不匹配的写法。
+func validateEventPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("event name cannot be empty")
+	}
+	if i := strings.Index(pattern, "*"); i >= 0 && i != len(pattern)-1 {
+		return fmt.Errorf("unsupported event pattern %q: wildcard is only allowed at the end", pattern)
+	}
+	return nil
+}
+
+// queuedEvent 在异步队列中携带发布时的 ctx，处理器可从中取得请求 ID 等信息。
+type queuedEvent struct {
+	ctx   context.Context
+	event DomainEvent
+}
+
+// subscription 是 EventBus 内部的一条订阅，异步模式下持有独立的有界队列。
+type subscription struct {
+	id        string
+	createdAt time.Time
+	active    atomic.Bool
+	pattern   string
+	filter    EventFilter
+	handler   EventHandler
+	options   EventPublishOptions
+	pipeline  EventHandlerFunc
+	queue     chan queuedEvent
+	closeOnce sync.Once
+
+	historyMu sync.Mutex
+	history   []EventPublishResult
+}
+
+func (s *subscription) snapshot(registry subscriptionRegistry) *EventSubscription {
+	return &EventSubscription{
+		ID:          s.id,
+		EventName:   s.pattern,
+		Handler:     s.handler,
+		HandlerName: s.handler.Name(),
+		CreatedAt:   s.createdAt,
+		IsActive:    s.active.Load(),
+		registry:    registry,
+	}
+}
+
+func (s *subscription) matches(event DomainEvent) bool {
+	if !s.active.Load() || !MatchEventName(s.pattern, event.EventName()) {
+		return false
+	}
+	return s.filter == nil || s.filter(event)
+}
+
+func (s *subscription) close() {
+	if s.queue == nil {
+		return
+	}
+	s.closeOnce.Do(func() { close(s.queue) })
+}
+
+// handle 按订阅选项执行处理器，失败后立即重试，返回最后一次的错误。
+func (s *subscription) handle(ctx context.Context, event DomainEvent) error {
+	err := s.handleWithRetry(ctx, event)
+	result := EventPublishResult{
+		EventName:   event.EventName(),
+		Success:     err == nil,
+		PublishedAt: time.Now(),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	s.recordResult(result)
+	return err
+}
+
+func (s *subscription) handleWithRetry(ctx context.Context, event DomainEvent) error {
+	var err error
+	for attempt := 0; attempt <= s.options.Retry; attempt++ {
+		if err = s.pipeline(ctx, event); err == nil {
+			return nil
+		}
+	}
+	if s.options.Retry > 0 {
+		return fmt.Errorf("failed after %d attempts: %w", s.options.Retry+1, err)
+	}
+	return err
+}
+
+func (s *subscription) recordResult(result EventPublishResult) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	s.history = append(s.history, result)
+	if len(s.history) > maxSubscriptionHistory {
+		s.history = s.history[len(s.history)-maxSubscriptionHistory:]
+	}
+}
+
+func (s *subscription) recentResults() []EventPublishResult {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	results := make([]EventPublishResult, len(s.history))
+	copy(results, s.history)
+	return results
+}
+
+// buildPipeline 组装一次处理调用：全局中间件在外、订阅中间件在内，之后是超时与处理器本身。
+// 恢复同时位于最外层与超时之内，超时 goroutine 中的 panic 与中间件自身的 panic 都会转为错误。
+func buildPipeline(handler EventHandler, options EventPublishOptions, global []EventMiddleware) EventHandlerFunc {
+	name := handler.Name()
+	inner := ChainEventMiddlewares(name, HandlerFunc(handler),
+		TimeoutMiddleware(options.Timeout),
+		RecoveryMiddleware(),
+	)
+
+	middlewares := make([]EventMiddleware, 0, len(global)+len(options.Middlewares)+1)
+	middlewares = append(middlewares, RecoveryMiddleware())
+	middlewares = append(middlewares, global...)
+	middlewares = append(middlewares, options.Middlewares...)
+	return ChainEventMiddlewares(name, inner, middlewares...)
+}
@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventHandlerFunc 是处理器调用的函数形式，ctx 来自 PublishContext，携带请求 ID、超时等信息。
+type EventHandlerFunc func(ctx context.Context, event DomainEvent) error
+
+// EventMiddleware 包装处理器调用，用于日志、恢复、超时、追踪与指标等横切逻辑。
+// handler 为被包装处理器的名称。
+type EventMiddleware func(handler string, next EventHandlerFunc) EventHandlerFunc
+
+// ChainEventMiddlewares 按顺序组合中间件，第一个中间件位于最外层。
+func ChainEventMiddlewares(handler string, final EventHandlerFunc, middlewares ...EventMiddleware) EventHandlerFunc {
+	next := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		next = middlewares[i](handler, next)
+	}
+	return next
+}
+
+// RecoveryMiddleware 将处理器的 panic 转为 ErrHandlerPanicked，避免单个处理器拖垮发布方或异步 worker。
+func RecoveryMiddleware() EventMiddleware {
+	return func(handler string, next EventHandlerFunc) EventHandlerFunc {
+		return func(ctx context.Context, event DomainEvent) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %v", ErrHandlerPanicked, r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// TimeoutMiddleware 在超时后立即返回 ErrHandlerTimeout。
+// 未实现 ContextEventHandler 的处理器无法被取消，会在后台继续运行直至返回。
+func TimeoutMiddleware(timeout time.Duration) EventMiddleware {
+	return func(handler string, next EventHandlerFunc) EventHandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, event DomainEvent) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- next(ctx, event)
+			}()
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return fmt.Errorf("%w after %s", ErrHandlerTimeout, timeout)
+			}
+		}
+	}
+}
+
+// HandlerFunc 将 EventHandler 适配为 EventHandlerFunc，实现 ContextEventHandler 时传入 ctx。
+func HandlerFunc(handler EventHandler) EventHandlerFunc {
+	if h, ok := handler.(ContextEventHandler); ok {
+		return h.HandleContext
+	}
+	return func(_ context.Context, event DomainEvent) error {
+		return handler.Handle(event)
+	}
+}
@@ -0,0 +1,362 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	name        string
+	aggregateID string
+}
+
+func (e testEvent) EventName() string      { return e.name }
+func (e testEvent) OccurredOn() time.Time  { return time.Unix(1700000000, 0) }
+func (e testEvent) GetAggregateID() string { return e.aggregateID }
+
+func newTestEvent(name string) testEvent {
+	return testEvent{name: name, aggregateID: "agg-1"}
+}
+
+func TestEventBusSyncRetriesAndRecoversPanics(t *testing.T) {
+	bus := NewEventBus()
+
+	var attempts atomic.Int32
+	flaky := NewFuncHandler("flaky", func(DomainEvent) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if _, err := bus.SubscribeWithOptions("order.placed", flaky, EventPublishOptions{Retry: 2}); err != nil {
+		t.Fatalf("SubscribeWithOptions() error = %v", err)
+	}
+	if err := bus.Publish(newTestEvent("order.placed")); err != nil {
+		t.Fatalf("Publish() error = %v, want success after retries", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+
+	panicking := NewFuncHandler("panicking", func(DomainEvent) error { panic("boom") })
+	if _, err := bus.Subscribe("order.cancelled", panicking); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	err := bus.Publish(newTestEvent("order.cancelled"))
+	if !errors.Is(err, ErrHandlerPanicked) {
+		t.Fatalf("Publish() error = %v, want ErrHandlerPanicked", err)
+	}
+}
+
+func TestEventBusTimeout(t *testing.T) {
+	bus := NewEventBus()
+	release := make(chan struct{})
+	defer close(release)
+
+	slow := NewFuncHandler("slow", func(DomainEvent) error {
+		<-release
+		return nil
+	})
+	if _, err := bus.SubscribeWithOptions("order.placed", slow, EventPublishOptions{Timeout: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("SubscribeWithOptions() error = %v", err)
+	}
+	if err := bus.Publish(newTestEvent("order.placed")); !errors.Is(err, ErrHandlerTimeout) {
+		t.Fatalf("Publish() error = %v, want ErrHandlerTimeout", err)
+	}
+}
+
+func TestEventBusAsyncDrainsOnClose(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		handled  []string
+		failures atomic.Int32
+	)
+	bus := NewEventBusWithOptions(EventBusOptions{
+		Async:     true,
+		QueueSize: 10,
+		OnError: func(DomainEvent, string, error) {
+			failures.Add(1)
+		},
+	})
+
+	recorder := NewFuncHandler("recorder", func(event DomainEvent) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		handled = append(handled, event.EventName())
+		mu.Unlock()
+		return nil
+	})
+	failing := NewFuncHandler("failing", func(DomainEvent) error { return errors.New("down") })
+	for _, handler := range []EventHandler{recorder, failing} {
+		if _, err := bus.Subscribe("order.placed", handler); err != nil {
+			t.Fatalf("Subscribe() error = %v", err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := bus.Publish(newTestEvent("order.placed")); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bus.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 5 {
+		t.Errorf("handled = %d, want 5 drained before Close returns", len(handled))
+	}
+	if got := failures.Load(); got != 5 {
+		t.Errorf("OnError calls = %d, want 5", got)
+	}
+	if err := bus.Publish(newTestEvent("order.placed")); !errors.Is(err, ErrEventBusClosed) {
+		t.Errorf("Publish() after Close error = %v, want ErrEventBusClosed", err)
+	}
+}
+
+func TestEventBusAsyncQueueFull(t *testing.T) {
+	bus := NewEventBusWithOptions(EventBusOptions{Async: true, QueueSize: 1})
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	blocking := NewFuncHandler("blocking", func(DomainEvent) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	if _, err := bus.Subscribe("order.placed", blocking); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// 第一条被 worker 取走并阻塞，第二条占满队列，第三条被拒绝。
+	if err := bus.Publish(newTestEvent("order.placed")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	<-started
+	if err := bus.Publish(newTestEvent("order.placed")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := bus.Publish(newTestEvent("order.placed")); !errors.Is(err, ErrEventQueueFull) {
+		t.Fatalf("Publish() error = %v, want ErrEventQueueFull", err)
+	}
+
+	close(release)
+	<-started
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestEventBusPatternSubscriptionsKeepOrder(t *testing.T) {
+	bus := NewEventBus()
+
+	var calls []string
+	record := func(name string) EventHandler {
+		return NewFuncHandler(name, func(event DomainEvent) error {
+			calls = append(calls, name+":"+event.EventName())
+			return nil
+		})
+	}
+
+	subscriptions := []struct {
+		pattern string
+		handler EventHandler
+	}{
+		{"*", record("audit")},
+		{"order.placed", record("exact")},
+		{"order.*", record("orders")},
+		{"user.*", record("users")},
+	}
+	for _, s := range subscriptions {
+		if _, err := bus.Subscribe(s.pattern, s.handler); err != nil {
+			t.Fatalf("Subscribe(%s) error = %v", s.pattern, err)
+		}
+	}
+	if _, err := bus.SubscribeFiltered("order.*", ForAggregate("agg-2"), record("agg-2")); err != nil {
+		t.Fatalf("SubscribeFiltered() error = %v", err)
+	}
+
+	if _, err := bus.Subscribe("order.*", record("orders")); err == nil {
+		t.Error("Subscribe() duplicate handler name on the same pattern: error = nil")
+	}
+	if _, err := bus.Subscribe("order.*.created", record("invalid")); err == nil {
+		t.Error("Subscribe() with inner wildcard: error = nil")
+	}
+
+	_ = bus.Publish(newTestEvent("order.placed"))
+	_ = bus.Publish(testEvent{name: "order.shipped", aggregateID: "agg-2"})
+
+	want := []string{
+		"audit:order.placed", "exact:order.placed", "orders:order.placed",
+		"audit:order.shipped", "orders:order.shipped", "agg-2:order.shipped",
+	}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestEventBusMiddlewareChain(t *testing.T) {
+	var calls []string
+	trace := func(label string) EventMiddleware {
+		return func(handler string, next EventHandlerFunc) EventHandlerFunc {
+			return func(ctx context.Context, event DomainEvent) error {
+				calls = append(calls, label+">"+handler)
+				err := next(ctx, event)
+				calls = append(calls, label+"<")
+				return err
+			}
+		}
+	}
+
+	type ctxKey struct{}
+	bus := NewEventBusWithOptions(EventBusOptions{
+		Middlewares: []EventMiddleware{trace("global-1"), trace("global-2")},
+	})
+	handler := NewFuncHandler("audit", func(DomainEvent) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	options := EventPublishOptions{Middlewares: []EventMiddleware{trace("local")}}
+	if _, err := bus.SubscribeWithOptions("*", handler, options); err != nil {
+		t.Fatalf("SubscribeWithOptions() error = %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "req-1")
+	if err := bus.PublishContext(ctx, newTestEvent("order.placed")); err != nil {
+		t.Fatalf("PublishContext() error = %v", err)
+	}
+
+	want := "global-1>audit,global-2>audit,local>audit,handler,local<,global-2<,global-1<"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestEventBusMiddlewareSeesRecoveredPanic(t *testing.T) {
+	var seen error
+	observe := func(handler string, next EventHandlerFunc) EventHandlerFunc {
+		return func(ctx context.Context, event DomainEvent) error {
+			seen = next(ctx, event)
+			return seen
+		}
+	}
+	bus := NewEventBusWithOptions(EventBusOptions{Middlewares: []EventMiddleware{observe}})
+	if _, err := bus.Subscribe("order.placed", NewFuncHandler("panicking", func(DomainEvent) error { panic("boom") })); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	_ = bus.Publish(newTestEvent("order.placed"))
+	if !errors.Is(seen, ErrHandlerPanicked) {
+		t.Errorf("middleware saw %v, want ErrHandlerPanicked", seen)
+	}
+}
+
+func TestEventSubscriptionHandle(t *testing.T) {
+	bus := NewEventBus()
+
+	var handled int
+	sub, err := bus.Subscribe("order.*", NewFuncHandler("counter", func(DomainEvent) error {
+		handled++
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if sub.ID == "" || !sub.IsActive || sub.HandlerName != "counter" {
+		t.Fatalf("subscription = %+v, want active handle with ID", sub)
+	}
+
+	_ = bus.Publish(newTestEvent("order.placed"))
+	if err := sub.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	_ = bus.Publish(newTestEvent("order.placed"))
+	if err := sub.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	_ = bus.Publish(newTestEvent("order.shipped"))
+
+	if handled != 2 {
+		t.Errorf("handled = %d, want 2 (paused event skipped)", handled)
+	}
+
+	statuses := bus.Subscriptions()
+	if len(statuses) != 1 || len(statuses[0].History) != 2 || !statuses[0].History[1].Success {
+		t.Fatalf("Subscriptions() = %+v, want one subscription with 2 successful results", statuses)
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if err := bus.Unsubscribe(sub.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Unsubscribe() twice error = %v, want ErrNotFound", err)
+	}
+	if len(bus.Subscriptions()) != 0 {
+		t.Error("Subscriptions() not empty after Unsubscribe")
+	}
+}
+
+func TestMockEventPublisherUnsubscribeUnknownID(t *testing.T) {
+	publisher := NewMockEventPublisher()
+	sub, err := publisher.Subscribe("order.placed", NewFuncHandler("counter", func(DomainEvent) error { return nil }))
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := publisher.Unsubscribe(sub.ID); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if err := publisher.Unsubscribe(sub.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Unsubscribe() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMockEventPublisherMatchesPatternsAndFilters(t *testing.T) {
+	publisher := NewMockEventPublisher()
+	handled := make(chan string, 3)
+	record := func(name string) EventHandler {
+		return NewFuncHandler(name, func(DomainEvent) error {
+			handled <- name
+			return nil
+		})
+	}
+	if _, err := publisher.Subscribe("order.*", record("wildcard")); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, err := publisher.SubscribeFiltered("order.placed", ForAggregate("agg-2"), record("other-aggregate")); err != nil {
+		t.Fatalf("SubscribeFiltered() error = %v", err)
+	}
+	if _, err := publisher.SubscribeFiltered("order.placed", ForAggregate("agg-1"), record("same-aggregate")); err != nil {
+		t.Fatalf("SubscribeFiltered() error = %v", err)
+	}
+
+	if err := publisher.Publish(newTestEvent("order.placed")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case name := <-handled:
+			got[name] = true
+		case <-time.After(time.Second):
+			t.Fatalf("handled = %v, want wildcard and same-aggregate", got)
+		}
+	}
+	if !got["wildcard"] || !got["same-aggregate"] {
+		t.Errorf("handled = %v, want wildcard and same-aggregate", got)
+	}
+	select {
+	case name := <-handled:
+		t.Errorf("%s handled an event rejected by its filter", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,77 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSagaStepRejected 表示步骤被业务规则拒绝（如库存不足、支付被拒），重试没有意义，saga 直接进入补偿。
+var ErrSagaStepRejected = errors.New("saga step rejected")
+
+// RejectSagaStep 将 err 标记为业务拒绝，errors.Is 对 ErrSagaStepRejected 与原错误均成立。
+func RejectSagaStep(err error) error {
+	return fmt.Errorf("%w: %w", ErrSagaStepRejected, err)
+}
+
+// SagaState 是 saga 实例在步骤之间传递的状态，Data 在每个步骤之后持久化。
+type SagaState struct {
+	ID            string
+	Type          string
+	CorrelationID string
+	Data          map[string]string
+	// Reason 为进入补偿的原因，补偿步骤可据此记录，例如作为取消订单的原因。
+	Reason string
+}
+
+// SagaStep 是 saga 的一个步骤。Action 与 Compensate 都必须幂等：
+// 超时、进程崩溃或租约过期后，同一步骤可能再次执行。
+type SagaStep struct {
+	Name string
+	// Action 推进流程，返回错误时按 MaxAttempts 重试，用尽或被拒绝后补偿已完成的步骤。
+	Action func(ctx context.Context, state *SagaState) error
+	// Compensate 撤销 Action 的效果，为空表示该步骤无需补偿。
+	Compensate func(ctx context.Context, state *SagaState) error
+	// Timeout 为单次执行的超时，超时按失败处理。
+	Timeout time.Duration
+	// MaxAttempts 为 Action 的最大尝试次数。
+	MaxAttempts int
+}
+
+// SagaDefinition 描述一类 saga：由哪个事件启动、哪些事件使其中止、依次执行哪些步骤。
+// 事件的聚合 ID 作为关联 ID，同一类型、同一关联 ID 只会启动一个实例。
+type SagaDefinition struct {
+	Type    string
+	StartOn string
+	// Start 从启动事件构造初始数据，返回 false 时不启动。
+	Start func(event DomainEvent) (map[string]string, bool)
+	// AbortOn 中的事件到达时，运行中的实例停止推进并补偿已完成的步骤。
+	AbortOn []string
+	Steps   []SagaStep
+}
+
+func (d SagaDefinition) Validate() error {
+	if d.Type == "" || d.StartOn == "" || d.Start == nil {
+		return fmt.Errorf("saga definition requires type, start event and start function")
+	}
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("saga %s has no steps", d.Type)
+	}
+	for i, step := range d.Steps {
+		if step.Name == "" || step.Action == nil {
+			return fmt.Errorf("saga %s step %d requires a name and an action", d.Type, i)
+		}
+	}
+	return nil
+}
+
+// AbortsOn 判断事件是否使该类 saga 中止。
+func (d SagaDefinition) AbortsOn(eventName string) bool {
+	for _, name := range d.AbortOn {
+		if name == eventName {
+			return true
+		}
+	}
+	return false
+}
@@ -18,6 +18,16 @@ func NewUserCreatedEvent(userID, name, email string) *UserCreatedEvent {
 	}
 }
 
+// RebuildUserCreatedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildUserCreatedEvent(userID, name, email string, occurredOn time.Time) *UserCreatedEvent {
+	return &UserCreatedEvent{
+		userID:     userID,
+		name:       name,
+		email:      email,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *UserCreatedEvent) EventName() string      { return "user.created" }
 func (e *UserCreatedEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *UserCreatedEvent) GetAggregateID() string { return e.userID }
@@ -37,6 +47,14 @@ func NewUserActivatedEvent(userID string) *UserActivatedEvent {
 	}
 }
 
+// RebuildUserActivatedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildUserActivatedEvent(userID string, occurredOn time.Time) *UserActivatedEvent {
+	return &UserActivatedEvent{
+		userID:     userID,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *UserActivatedEvent) EventName() string      { return "user.activated" }
 func (e *UserActivatedEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *UserActivatedEvent) GetAggregateID() string { return e.userID }
@@ -54,6 +72,14 @@ func NewUserDeactivatedEvent(userID string) *UserDeactivatedEvent {
 	}
 }
 
+// RebuildUserDeactivatedEvent 仅供事件反序列化使用，保留原始发生时间。
+func RebuildUserDeactivatedEvent(userID string, occurredOn time.Time) *UserDeactivatedEvent {
+	return &UserDeactivatedEvent{
+		userID:     userID,
+		occurredOn: occurredOn,
+	}
+}
+
 func (e *UserDeactivatedEvent) EventName() string      { return "user.deactivated" }
 func (e *UserDeactivatedEvent) OccurredOn() time.Time  { return e.occurredOn }
 func (e *UserDeactivatedEvent) GetAggregateID() string { return e.userID }
@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"ddd/domain/shared"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics 记录事件处理的耗时与失败次数，同时实现 prometheus.Collector。
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "ddd",
+			Subsystem: "event_handler",
+			Name:      "duration_seconds",
+			Help:      "Latency of in-process domain event handlers, including failed attempts.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"event_type", "handler"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ddd",
+			Subsystem: "event_handler",
+			Name:      "failures_total",
+			Help:      "Failed in-process domain event handler attempts.",
+		}, []string{"event_type", "handler"}),
+	}
+}
+
+// Middleware 返回记录指标的中间件，每次重试单独计数。
+func (m *Metrics) Middleware() shared.EventMiddleware {
+	return func(handler string, next shared.EventHandlerFunc) shared.EventHandlerFunc {
+		return func(ctx context.Context, event shared.DomainEvent) error {
+			start := time.Now()
+			err := next(ctx, event)
+			m.duration.WithLabelValues(event.EventName(), handler).Observe(time.Since(start).Seconds())
+			if err != nil {
+				m.failures.WithLabelValues(event.EventName(), handler).Inc()
+			}
+			return err
+		}
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.failures.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.failures.Collect(ch)
+}
+
+var _ prometheus.Collector = (*Metrics)(nil)
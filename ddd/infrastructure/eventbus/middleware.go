@@ -0,0 +1,66 @@
+/*
+Package eventbus 提供进程内 shared.EventBus 的基础设施中间件：日志、链路追踪与 Prometheus 指标。
+
+恢复与超时中间件不依赖外部组件，位于 domain/shared。
+*/
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Logging 记录每次处理的耗时与结果，ctx 中有请求 ID 时一并输出，便于与触发事件的 HTTP 请求关联。
+func Logging() shared.EventMiddleware {
+	return func(handler string, next shared.EventHandlerFunc) shared.EventHandlerFunc {
+		return func(ctx context.Context, event shared.DomainEvent) error {
+			start := time.Now()
+			err := next(ctx, event)
+
+			log := logger.With()
+			if requestID := persistence.RequestIDFromContext(ctx); requestID != "" {
+				log = logger.WithRequestID(requestID)
+			}
+			fields := []zap.Field{
+				zap.String("event_type", event.EventName()),
+				zap.String("aggregate_id", event.GetAggregateID()),
+				zap.String("handler", handler),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				log.Warn("Domain event handler failed", append(fields, zap.Error(err))...)
+				return err
+			}
+			log.Debug("Domain event handled", fields...)
+			return nil
+		}
+	}
+}
+
+// Tracer 抽象链路追踪实现，可用 OpenTelemetry 等 SDK 适配，事件总线不直接依赖具体库。
+// Start 返回携带新 span 的 ctx，以及在处理结束时调用的 end。
+type Tracer interface {
+	Start(ctx context.Context, spanName string, attributes map[string]string) (context.Context, func(err error))
+}
+
+// Tracing 为每次处理创建一个 span，处理器经 ctx 发起的下游调用会成为其子 span。
+func Tracing(tracer Tracer) shared.EventMiddleware {
+	return func(handler string, next shared.EventHandlerFunc) shared.EventHandlerFunc {
+		return func(ctx context.Context, event shared.DomainEvent) error {
+			ctx, end := tracer.Start(ctx, "event.handle "+handler, map[string]string{
+				"event.type":         event.EventName(),
+				"event.aggregate_id": event.GetAggregateID(),
+				"event.handler":      handler,
+			})
+			err := next(ctx, event)
+			end(err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ddd/domain/shared"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testEvent struct{}
+
+func (testEvent) EventName() string      { return "order.placed" }
+func (testEvent) OccurredOn() time.Time  { return time.Unix(1700000000, 0) }
+func (testEvent) GetAggregateID() string { return "order-1" }
+
+type recordingTracer struct {
+	name       string
+	attributes map[string]string
+	ended      bool
+	err        error
+}
+
+func (t *recordingTracer) Start(ctx context.Context, spanName string, attributes map[string]string) (context.Context, func(error)) {
+	t.name = spanName
+	t.attributes = attributes
+	return ctx, func(err error) {
+		t.ended = true
+		t.err = err
+	}
+}
+
+func TestTracingAndMetricsMiddlewares(t *testing.T) {
+	tracer := &recordingTracer{}
+	metrics := NewMetrics()
+	bus := shared.NewEventBusWithOptions(shared.EventBusOptions{
+		Middlewares: []shared.EventMiddleware{Logging(), Tracing(tracer), metrics.Middleware()},
+	})
+
+	failure := errors.New("read model unavailable")
+	handler := shared.NewFuncHandler("projector", func(shared.DomainEvent) error { return failure })
+	if _, err := bus.Subscribe("order.*", handler); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := bus.Publish(testEvent{}); !errors.Is(err, failure) {
+		t.Fatalf("Publish() error = %v, want handler error", err)
+	}
+
+	if tracer.name != "event.handle projector" || !tracer.ended || !errors.Is(tracer.err, failure) {
+		t.Errorf("span = %q ended=%v err=%v", tracer.name, tracer.ended, tracer.err)
+	}
+	if tracer.attributes["event.type"] != "order.placed" {
+		t.Errorf("span attributes = %v", tracer.attributes)
+	}
+	if got := testutil.ToFloat64(metrics.failures.WithLabelValues("order.placed", "projector")); got != 1 {
+		t.Errorf("failures = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(metrics, "ddd_event_handler_duration_seconds"); got != 1 {
+		t.Errorf("duration series = %d, want 1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package eventbus
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName 是事件总线 span 的 instrumentation scope 名称。
+const TracerName = "ddd/infrastructure/eventbus"
+
+// OTelTracer 以 OpenTelemetry 实现 Tracer。
+type OTelTracer struct {
+	tracer trace.Tracer
+}
+
+// NewOTelTracer 使用 provider 创建 Tracer；provider 为 nil 时使用 otel 全局 TracerProvider，
+// 未注册 SDK 时全局实现不记录任何 span。
+func NewOTelTracer(provider trace.TracerProvider) *OTelTracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &OTelTracer{tracer: provider.Tracer(TracerName)}
+}
+
+func (t *OTelTracer) Start(ctx context.Context, spanName string, attributes map[string]string) (context.Context, func(err error)) {
+	attrs := make([]attribute.KeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	ctx, span := t.tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+var _ Tracer = (*OTelTracer)(nil)
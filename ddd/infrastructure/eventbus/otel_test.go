@@ -0,0 +1,50 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ddd/domain/shared"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOTelTracerRecordsHandlerSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	bus := shared.NewEventBusWithOptions(shared.EventBusOptions{
+		Middlewares: []shared.EventMiddleware{Tracing(NewOTelTracer(provider))},
+	})
+
+	failure := errors.New("read model unavailable")
+	if _, err := bus.Subscribe("order.*", shared.NewFuncHandler("projector", func(shared.DomainEvent) error { return failure })); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := bus.Publish(testEvent{}); !errors.Is(err, failure) {
+		t.Fatalf("Publish() error = %v, want handler error", err)
+	}
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "event.handle projector" || span.Status().Code != codes.Error {
+		t.Errorf("span = %q status %v, want failed event.handle projector", span.Name(), span.Status())
+	}
+	want := attribute.String("event.type", "order.placed")
+	found := false
+	for _, attr := range span.Attributes() {
+		found = found || attr == want
+	}
+	if !found {
+		t.Errorf("span attributes = %v, want %v", span.Attributes(), want)
+	}
+}
@@ -0,0 +1,153 @@
+package eventcodec
+
+import (
+	"ddd/domain/order"
+	"ddd/domain/shared"
+)
+
+type orderPlacedPayload struct {
+	Metadata
+	OrderID       string `json:"order_id"`
+	UserID        string `json:"user_id"`
+	TotalAmount   int64  `json:"total_amount"`
+	TotalCurrency string `json:"total_currency"`
+}
+
+type orderStatusPayload struct {
+	Metadata
+	OrderID string `json:"order_id"`
+}
+
+type orderCancelledPayload struct {
+	Metadata
+	OrderID string `json:"order_id"`
+	Reason  string `json:"reason"`
+}
+
+type orderItemAddedPayload struct {
+	Metadata
+	OrderID          string `json:"order_id"`
+	ItemID           string `json:"item_id"`
+	ProductID        string `json:"product_id"`
+	ProductName      string `json:"product_name"`
+	Quantity         int    `json:"quantity"`
+	UnitPrice        int64  `json:"unit_price"`
+	UnitCurrency     string `json:"unit_currency"`
+	Subtotal         int64  `json:"subtotal"`
+	SubtotalCurrency string `json:"subtotal_currency"`
+}
+
+type orderItemRemovedPayload struct {
+	Metadata
+	OrderID string `json:"order_id"`
+	ItemID  string `json:"item_id"`
+}
+
+func RegisterOrderEvents(r *Registry) error {
+	if err := Register(r, "order.placed", 1,
+		func(e *order.OrderPlacedEvent) orderPlacedPayload {
+			total := e.TotalAmount()
+			return orderPlacedPayload{
+				Metadata:      MetadataOf(e),
+				OrderID:       e.OrderID(),
+				UserID:        e.UserID(),
+				TotalAmount:   total.Amount(),
+				TotalCurrency: total.Currency(),
+			}
+		},
+		func(p orderPlacedPayload) (*order.OrderPlacedEvent, error) {
+			total := shared.NewMoney(p.TotalAmount, p.TotalCurrency)
+			return order.RebuildOrderPlacedEvent(p.OrderID, p.UserID, *total, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "order.confirmed", 1,
+		func(e *order.OrderConfirmedEvent) orderStatusPayload {
+			return orderStatusPayload{Metadata: MetadataOf(e), OrderID: e.OrderID()}
+		},
+		func(p orderStatusPayload) (*order.OrderConfirmedEvent, error) {
+			return order.RebuildOrderConfirmedEvent(p.OrderID, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "order.shipped", 1,
+		func(e *order.OrderShippedEvent) orderStatusPayload {
+			return orderStatusPayload{Metadata: MetadataOf(e), OrderID: e.OrderID()}
+		},
+		func(p orderStatusPayload) (*order.OrderShippedEvent, error) {
+			return order.RebuildOrderShippedEvent(p.OrderID, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "order.delivered", 1,
+		func(e *order.OrderDeliveredEvent) orderStatusPayload {
+			return orderStatusPayload{Metadata: MetadataOf(e), OrderID: e.OrderID()}
+		},
+		func(p orderStatusPayload) (*order.OrderDeliveredEvent, error) {
+			return order.RebuildOrderDeliveredEvent(p.OrderID, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "order.item_added", 1,
+		func(e *order.OrderItemAddedEvent) orderItemAddedPayload {
+			item := e.Item()
+			return orderItemAddedPayload{
+				Metadata:         MetadataOf(e),
+				OrderID:          e.OrderID(),
+				ItemID:           item.ID(),
+				ProductID:        item.ProductID(),
+				ProductName:      item.ProductName(),
+				Quantity:         item.Quantity(),
+				UnitPrice:        item.UnitPrice().Amount(),
+				UnitCurrency:     item.UnitPrice().Currency(),
+				Subtotal:         item.Subtotal().Amount(),
+				SubtotalCurrency: item.Subtotal().Currency(),
+			}
+		},
+		func(p orderItemAddedPayload) (*order.OrderItemAddedEvent, error) {
+			item := order.RebuildItemFromDTO(order.ItemReconstructionDTO{
+				ID:          p.ItemID,
+				ProductID:   p.ProductID,
+				ProductName: p.ProductName,
+				Quantity:    p.Quantity,
+				UnitPrice:   *shared.NewMoney(p.UnitPrice, p.UnitCurrency),
+				Subtotal:    *shared.NewMoney(p.Subtotal, p.SubtotalCurrency),
+			})
+			return order.RebuildOrderItemAddedEvent(p.OrderID, item, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "order.item_removed", 1,
+		func(e *order.OrderItemRemovedEvent) orderItemRemovedPayload {
+			return orderItemRemovedPayload{Metadata: MetadataOf(e), OrderID: e.OrderID(), ItemID: e.ItemID()}
+		},
+		func(p orderItemRemovedPayload) (*order.OrderItemRemovedEvent, error) {
+			return order.RebuildOrderItemRemovedEvent(p.OrderID, p.ItemID, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	return Register(r, "order.cancelled", 1,
+		func(e *order.OrderCancelledEvent) orderCancelledPayload {
+			return orderCancelledPayload{
+				Metadata: MetadataOf(e),
+				OrderID:  e.OrderID(),
+				Reason:   e.Reason(),
+			}
+		},
+		func(p orderCancelledPayload) (*order.OrderCancelledEvent, error) {
+			return order.RebuildOrderCancelledEvent(p.OrderID, p.Reason, p.OccurredOn), nil
+		},
+	)
+}
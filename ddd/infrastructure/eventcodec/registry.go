@@ -0,0 +1,170 @@
+/*
+Package eventcodec 提供领域事件的显式序列化注册表。
+
+每种事件类型注册一对编码/解码函数及其 schema 版本，未注册的事件在写入
+outbox 时直接报错，避免字段被静默丢弃。旧版本的 payload 在解码前经由
+upcaster 链逐级升级到当前版本，见 RegisterUpcaster。
+*/
+package eventcodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"ddd/domain/shared"
+)
+
+// Metadata 是所有事件 payload 共有的字段。
+type Metadata struct {
+	EventName   string    `json:"event_name"`
+	AggregateID string    `json:"aggregate_id"`
+	OccurredOn  time.Time `json:"occurred_on"`
+}
+
+func MetadataOf(event shared.DomainEvent) Metadata {
+	return Metadata{
+		EventName:   event.EventName(),
+		AggregateID: event.GetAggregateID(),
+		OccurredOn:  event.OccurredOn(),
+	}
+}
+
+// Encoded 是序列化后的事件及其 schema 版本。
+type Encoded struct {
+	EventType string
+	Version   int
+	Payload   []byte
+}
+
+type codec struct {
+	version int
+	encode  func(shared.DomainEvent) ([]byte, error)
+	decode  func([]byte) (shared.DomainEvent, error)
+}
+
+type Registry struct {
+	mu        sync.RWMutex
+	codecs    map[string]codec
+	upcasters map[upcasterKey]Upcaster
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		codecs:    make(map[string]codec),
+		upcasters: make(map[upcasterKey]Upcaster),
+	}
+}
+
+// Register 为事件类型 E 注册编码与解码函数，P 为该版本 payload 的 JSON 结构。
+func Register[E shared.DomainEvent, P any](
+	r *Registry,
+	eventType string,
+	version int,
+	encode func(E) P,
+	decode func(P) (E, error),
+) error {
+	if eventType == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if version < 1 {
+		return fmt.Errorf("event %s: version must be positive", eventType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.codecs[eventType]; exists {
+		return fmt.Errorf("event %s is already registered", eventType)
+	}
+
+	r.codecs[eventType] = codec{
+		version: version,
+		encode: func(event shared.DomainEvent) ([]byte, error) {
+			typed, ok := event.(E)
+			if !ok {
+				return nil, fmt.Errorf("event %s has unexpected type %T", eventType, event)
+			}
+			return json.Marshal(encode(typed))
+		},
+		decode: func(data []byte) (shared.DomainEvent, error) {
+			var payload P
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return nil, err
+			}
+			return decode(payload)
+		},
+	}
+	return nil
+}
+
+func (r *Registry) lookup(eventType string) (codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.codecs[eventType]
+	return c, ok
+}
+
+// Encode 使用事件类型注册的编码器序列化事件。
+func (r *Registry) Encode(event shared.DomainEvent) (Encoded, error) {
+	c, ok := r.lookup(event.EventName())
+	if !ok {
+		return Encoded{}, fmt.Errorf("no codec registered for event %s", event.EventName())
+	}
+	payload, err := c.encode(event)
+	if err != nil {
+		return Encoded{}, fmt.Errorf("failed to encode event %s: %w", event.EventName(), err)
+	}
+	return Encoded{
+		EventType: event.EventName(),
+		Version:   c.version,
+		Payload:   payload,
+	}, nil
+}
+
+// Decode 将 payload 升级到当前 schema 版本后还原为具体的领域事件类型。
+func (r *Registry) Decode(eventType string, version int, payload []byte) (shared.DomainEvent, error) {
+	raw, err := r.Upcast(RawEvent{EventType: eventType, Version: version, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := r.lookup(raw.EventType)
+	if !ok {
+		return nil, fmt.Errorf("no codec registered for event %s", raw.EventType)
+	}
+	if raw.Version != c.version {
+		return nil, fmt.Errorf("event %s: unsupported version %d, current is %d", raw.EventType, raw.Version, c.version)
+	}
+	event, err := c.decode(raw.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", raw.EventType, err)
+	}
+	return event, nil
+}
+
+// Version 返回事件类型当前的 schema 版本。
+func (r *Registry) Version(eventType string) (int, bool) {
+	c, ok := r.lookup(eventType)
+	return c.version, ok
+}
+
+var defaultRegistry = newDefaultRegistry()
+
+// Default 返回注册了全部领域事件的注册表。
+func Default() *Registry {
+	return defaultRegistry
+}
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, register := range []func(*Registry) error{
+		RegisterOrderEvents,
+		RegisterUserEvents,
+	} {
+		if err := register(r); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
@@ -0,0 +1,94 @@
+package eventcodec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/domain/user"
+)
+
+func TestDefaultRegistryRoundTrip(t *testing.T) {
+	occurredOn := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	events := []shared.DomainEvent{
+		order.RebuildOrderPlacedEvent("order-1", "user-1", *shared.NewMoney(1999, "CNY"), occurredOn),
+		order.RebuildOrderConfirmedEvent("order-1", occurredOn),
+		order.RebuildOrderShippedEvent("order-1", occurredOn),
+		order.RebuildOrderDeliveredEvent("order-1", occurredOn),
+		order.RebuildOrderCancelledEvent("order-1", "out of stock", occurredOn),
+		order.RebuildOrderItemAddedEvent("order-1", order.RebuildItemFromDTO(order.ItemReconstructionDTO{
+			ID:          "item-1",
+			ProductID:   "product-1",
+			ProductName: "Keyboard",
+			Quantity:    2,
+			UnitPrice:   *shared.NewMoney(500, "CNY"),
+			Subtotal:    *shared.NewMoney(1000, "CNY"),
+		}), occurredOn),
+		order.RebuildOrderItemRemovedEvent("order-1", "item-1", occurredOn),
+		user.RebuildUserCreatedEvent("user-1", "Alice", "alice@example.com", occurredOn),
+		user.RebuildUserActivatedEvent("user-1", occurredOn),
+		user.RebuildUserDeactivatedEvent("user-1", occurredOn),
+	}
+
+	registry := Default()
+	for _, event := range events {
+		t.Run(event.EventName(), func(t *testing.T) {
+			encoded, err := registry.Encode(event)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if encoded.Version != 1 {
+				t.Errorf("Version = %d, want 1", encoded.Version)
+			}
+
+			decoded, err := registry.Decode(encoded.EventType, encoded.Version, encoded.Payload)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, event) {
+				t.Errorf("Decode() = %#v, want %#v", decoded, event)
+			}
+		})
+	}
+}
+
+func TestOrderCancelledPayloadKeepsReason(t *testing.T) {
+	encoded, err := Default().Encode(order.NewOrderCancelledEvent("order-1", "customer request"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(encoded.Payload, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["reason"] != "customer request" {
+		t.Errorf("reason = %v, want customer request", payload["reason"])
+	}
+	if payload["aggregate_id"] != "order-1" || payload["event_name"] != "order.cancelled" {
+		t.Errorf("metadata = %v, want aggregate_id and event_name", payload)
+	}
+}
+
+type unregisteredEvent struct{}
+
+func (unregisteredEvent) EventName() string      { return "test.unregistered" }
+func (unregisteredEvent) OccurredOn() time.Time  { return time.Now() }
+func (unregisteredEvent) GetAggregateID() string { return "agg-1" }
+
+func TestRegistryRejectsUnknownEventsAndVersions(t *testing.T) {
+	registry := Default()
+
+	if _, err := registry.Encode(unregisteredEvent{}); err == nil {
+		t.Error("Encode() error = nil, want error for unregistered event")
+	}
+	if _, err := registry.Decode("order.confirmed", 99, []byte(`{}`)); err == nil {
+		t.Error("Decode() error = nil, want error for unsupported version")
+	}
+	if err := RegisterOrderEvents(registry); err == nil {
+		t.Error("RegisterOrderEvents() error = nil, want duplicate registration error")
+	}
+}
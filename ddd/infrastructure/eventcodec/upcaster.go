@@ -0,0 +1,97 @@
+package eventcodec
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// maxUpcastSteps 防止配置错误的 upcaster 形成环。
+const maxUpcastSteps = 64
+
+// RawEvent 是尚未解码的事件 payload 及其类型与 schema 版本。
+type RawEvent struct {
+	EventType string
+	Version   int
+	Payload   json.RawMessage
+}
+
+// Upcaster 将某个类型与版本的 payload 升级一步。
+// 返回值的版本必须更高，或改为新的事件类型（用于事件改名）。
+type Upcaster func(RawEvent) (RawEvent, error)
+
+type upcasterKey struct {
+	eventType string
+	version   int
+}
+
+// RegisterUpcaster 注册从 eventType 的 fromVersion 升级的函数，
+// 多个 upcaster 首尾相接组成 v1 -> v2 -> v3 的升级链。
+func (r *Registry) RegisterUpcaster(eventType string, fromVersion int, upcaster Upcaster) error {
+	if eventType == "" || fromVersion < 1 {
+		return fmt.Errorf("upcaster requires event type and positive version")
+	}
+	if upcaster == nil {
+		return fmt.Errorf("upcaster for %s v%d is nil", eventType, fromVersion)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := upcasterKey{eventType: eventType, version: fromVersion}
+	if _, exists := r.upcasters[key]; exists {
+		return fmt.Errorf("upcaster for %s v%d is already registered", eventType, fromVersion)
+	}
+	r.upcasters[key] = upcaster
+	return nil
+}
+
+// UpcastFields 是仅增删或改名字段时的便捷写法：将 payload 解为 map 交给 fn 修改，
+// 并将版本加一。
+func UpcastFields(fn func(fields map[string]interface{}) error) Upcaster {
+	return func(raw RawEvent) (RawEvent, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(raw.Payload, &fields); err != nil {
+			return RawEvent{}, err
+		}
+		if err := fn(fields); err != nil {
+			return RawEvent{}, err
+		}
+		payload, err := json.Marshal(fields)
+		if err != nil {
+			return RawEvent{}, err
+		}
+		return RawEvent{EventType: raw.EventType, Version: raw.Version + 1, Payload: payload}, nil
+	}
+}
+
+func (r *Registry) upcaster(eventType string, version int) (Upcaster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	upcaster, ok := r.upcasters[upcasterKey{eventType: eventType, version: version}]
+	return upcaster, ok
+}
+
+// Upcast 依次应用已注册的 upcaster，直到没有可用的下一步。
+// 没有注册 upcaster 的事件原样返回，因此可以安全地用于任意 payload。
+func (r *Registry) Upcast(raw RawEvent) (RawEvent, error) {
+	for step := 0; ; step++ {
+		upcaster, ok := r.upcaster(raw.EventType, raw.Version)
+		if !ok {
+			return raw, nil
+		}
+		if step >= maxUpcastSteps {
+			return RawEvent{}, fmt.Errorf("event %s: upcaster chain exceeds %d steps", raw.EventType, maxUpcastSteps)
+		}
+
+		next, err := upcaster(raw)
+		if err != nil {
+			return RawEvent{}, fmt.Errorf("failed to upcast event %s v%d: %w", raw.EventType, raw.Version, err)
+		}
+		if next.EventType == "" {
+			next.EventType = raw.EventType
+		}
+		if next.EventType == raw.EventType && next.Version <= raw.Version {
+			return RawEvent{}, fmt.Errorf("upcaster for %s v%d did not advance the version", raw.EventType, raw.Version)
+		}
+		raw = next
+	}
+}
@@ -0,0 +1,126 @@
+package eventcodec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type placedEvent struct {
+	orderID    string
+	amount     int64
+	currency   string
+	occurredOn time.Time
+}
+
+func (e *placedEvent) EventName() string      { return "test.placed" }
+func (e *placedEvent) OccurredOn() time.Time  { return e.occurredOn }
+func (e *placedEvent) GetAggregateID() string { return e.orderID }
+
+// placedPayloadV3 是当前版本：v2 增加 currency，v3 将 total 改名为 amount。
+type placedPayloadV3 struct {
+	Metadata
+	OrderID  string `json:"order_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func newUpcastingRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	err := Register(r, "test.placed", 3,
+		func(e *placedEvent) placedPayloadV3 {
+			return placedPayloadV3{Metadata: MetadataOf(e), OrderID: e.orderID, Amount: e.amount, Currency: e.currency}
+		},
+		func(p placedPayloadV3) (*placedEvent, error) {
+			return &placedEvent{orderID: p.OrderID, amount: p.Amount, currency: p.Currency, occurredOn: p.OccurredOn}, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	upcasters := []struct {
+		eventType string
+		version   int
+		upcaster  Upcaster
+	}{
+		{"legacy.order_placed", 1, func(raw RawEvent) (RawEvent, error) {
+			return RawEvent{EventType: "test.placed", Version: 1, Payload: raw.Payload}, nil
+		}},
+		{"test.placed", 1, UpcastFields(func(fields map[string]interface{}) error {
+			fields["currency"] = "CNY"
+			return nil
+		})},
+		{"test.placed", 2, UpcastFields(func(fields map[string]interface{}) error {
+			fields["amount"] = fields["total"]
+			delete(fields, "total")
+			return nil
+		})},
+	}
+	for _, u := range upcasters {
+		if err := r.RegisterUpcaster(u.eventType, u.version, u.upcaster); err != nil {
+			t.Fatalf("RegisterUpcaster() error = %v", err)
+		}
+	}
+	return r
+}
+
+func TestDecodeAppliesUpcasterChain(t *testing.T) {
+	r := newUpcastingRegistry(t)
+	occurredOn := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		eventType string
+		version   int
+		payload   string
+	}{
+		{"v1", "test.placed", 1, `{"order_id":"o-1","total":100,"occurred_on":"2024-01-01T00:00:00Z"}`},
+		{"v2", "test.placed", 2, `{"order_id":"o-1","total":100,"currency":"CNY","occurred_on":"2024-01-01T00:00:00Z"}`},
+		{"v3", "test.placed", 3, `{"order_id":"o-1","amount":100,"currency":"CNY","occurred_on":"2024-01-01T00:00:00Z"}`},
+		{"renamed", "legacy.order_placed", 1, `{"order_id":"o-1","total":100,"occurred_on":"2024-01-01T00:00:00Z"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := r.Decode(tc.eventType, tc.version, []byte(tc.payload))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			placed, ok := event.(*placedEvent)
+			if !ok {
+				t.Fatalf("Decode() = %T, want *placedEvent", event)
+			}
+			want := placedEvent{orderID: "o-1", amount: 100, currency: "CNY", occurredOn: occurredOn}
+			if *placed != want {
+				t.Errorf("Decode() = %+v, want %+v", *placed, want)
+			}
+		})
+	}
+}
+
+func TestUpcastLeavesUnknownEventsUntouched(t *testing.T) {
+	r := newUpcastingRegistry(t)
+	raw := RawEvent{EventType: "other.event", Version: 1, Payload: json.RawMessage(`{"a":1}`)}
+
+	got, err := r.Upcast(raw)
+	if err != nil {
+		t.Fatalf("Upcast() error = %v", err)
+	}
+	if got.EventType != raw.EventType || got.Version != raw.Version || string(got.Payload) != string(raw.Payload) {
+		t.Errorf("Upcast() = %+v, want %+v", got, raw)
+	}
+}
+
+func TestUpcastRejectsNonAdvancingUpcaster(t *testing.T) {
+	r := NewRegistry()
+	if err := r.RegisterUpcaster("test.loop", 1, func(raw RawEvent) (RawEvent, error) {
+		return raw, nil
+	}); err != nil {
+		t.Fatalf("RegisterUpcaster() error = %v", err)
+	}
+
+	if _, err := r.Upcast(RawEvent{EventType: "test.loop", Version: 1}); err == nil {
+		t.Error("Upcast() error = nil, want error for upcaster that does not advance")
+	}
+}
@@ -0,0 +1,55 @@
+package eventcodec
+
+import (
+	"ddd/domain/user"
+)
+
+type userCreatedPayload struct {
+	Metadata
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+type userStatusPayload struct {
+	Metadata
+	UserID string `json:"user_id"`
+}
+
+func RegisterUserEvents(r *Registry) error {
+	if err := Register(r, "user.created", 1,
+		func(e *user.UserCreatedEvent) userCreatedPayload {
+			return userCreatedPayload{
+				Metadata: MetadataOf(e),
+				UserID:   e.UserID(),
+				Name:     e.Name(),
+				Email:    e.Email(),
+			}
+		},
+		func(p userCreatedPayload) (*user.UserCreatedEvent, error) {
+			return user.RebuildUserCreatedEvent(p.UserID, p.Name, p.Email, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if err := Register(r, "user.activated", 1,
+		func(e *user.UserActivatedEvent) userStatusPayload {
+			return userStatusPayload{Metadata: MetadataOf(e), UserID: e.UserID()}
+		},
+		func(p userStatusPayload) (*user.UserActivatedEvent, error) {
+			return user.RebuildUserActivatedEvent(p.UserID, p.OccurredOn), nil
+		},
+	); err != nil {
+		return err
+	}
+
+	return Register(r, "user.deactivated", 1,
+		func(e *user.UserDeactivatedEvent) userStatusPayload {
+			return userStatusPayload{Metadata: MetadataOf(e), UserID: e.UserID()}
+		},
+		func(p userStatusPayload) (*user.UserDeactivatedEvent, error) {
+			return user.RebuildUserDeactivatedEvent(p.UserID, p.OccurredOn), nil
+		},
+	)
+}
@@ -0,0 +1,71 @@
+/*
+Package fulfilment 提供订单履约 saga 所需端口的模拟实现，供尚未接入库存与支付系统的环境使用。
+*/
+package fulfilment
+
+import (
+	"context"
+	"fmt"
+
+	orderapp "ddd/application/order"
+	"ddd/domain/shared"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SimulatedInventory 总是预留成功，预留 ID 由订单 ID 派生，重复调用结果一致。
+type SimulatedInventory struct{}
+
+func NewSimulatedInventory() *SimulatedInventory {
+	return &SimulatedInventory{}
+}
+
+func (i *SimulatedInventory) Reserve(ctx context.Context, orderID string, items []orderapp.StockItem) (string, error) {
+	reservationID := "res-" + orderID
+	logger.Info("Simulated stock reserved",
+		zap.String("order_id", orderID),
+		zap.String("reservation_id", reservationID),
+		zap.Int("lines", len(items)),
+	)
+	return reservationID, nil
+}
+
+func (i *SimulatedInventory) Release(ctx context.Context, reservationID string) error {
+	logger.Info("Simulated stock released", zap.String("reservation_id", reservationID))
+	return nil
+}
+
+// SimulatedPayments 拒绝金额超过 limit 的扣款（limit 不大于 0 时不限制），便于演练补偿流程。
+type SimulatedPayments struct {
+	limit int64
+}
+
+func NewSimulatedPayments(limit int64) *SimulatedPayments {
+	return &SimulatedPayments{limit: limit}
+}
+
+func (p *SimulatedPayments) Charge(ctx context.Context, orderID, userID string, amount shared.Money) (string, error) {
+	if p.limit > 0 && amount.Amount() > p.limit {
+		return "", shared.RejectSagaStep(fmt.Errorf("payment of %s declined: exceeds limit %d", amount.String(), p.limit))
+	}
+	paymentID := "pay-" + orderID
+	logger.Info("Simulated payment captured",
+		zap.String("order_id", orderID),
+		zap.String("user_id", userID),
+		zap.String("payment_id", paymentID),
+		zap.Int64("amount", amount.Amount()),
+		zap.String("currency", amount.Currency()),
+	)
+	return paymentID, nil
+}
+
+func (p *SimulatedPayments) Refund(ctx context.Context, paymentID string) error {
+	logger.Info("Simulated payment refunded", zap.String("payment_id", paymentID))
+	return nil
+}
+
+var (
+	_ orderapp.InventoryService = (*SimulatedInventory)(nil)
+	_ orderapp.PaymentService   = (*SimulatedPayments)(nil)
+)
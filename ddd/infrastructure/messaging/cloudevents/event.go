@@ -0,0 +1,104 @@
+/*
+Package cloudevents 将 outbox 事件封装为 CloudEvents 1.0 信封。
+
+支持 HTTP 协议绑定的两种内容模式：
+
+  - structured：请求体为完整的 JSON 信封，Content-Type 为 application/cloudevents+json
+  - binary：请求体为事件数据，上下文属性放在 ce-* 请求头中
+
+信封的 id 取 outbox 事件 ID，重试与重放时保持不变，消费方可据此去重。
+*/
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ddd/infrastructure/persistence/mysql"
+)
+
+const (
+	SpecVersion = "1.0"
+
+	ContentTypeJSON       = "application/json"
+	ContentTypeCloudEvent = "application/cloudevents+json"
+
+	HeaderSpecVersion = "ce-specversion"
+	HeaderID          = "ce-id"
+	HeaderSource      = "ce-source"
+	HeaderType        = "ce-type"
+	HeaderSubject     = "ce-subject"
+	HeaderTime        = "ce-time"
+)
+
+// Mode 表示 CloudEvents 的内容模式。
+type Mode string
+
+const (
+	ModeStructured Mode = "structured"
+	ModeBinary     Mode = "binary"
+)
+
+func ParseMode(mode string) (Mode, error) {
+	switch Mode(mode) {
+	case ModeStructured, ModeBinary:
+		return Mode(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported cloudevents mode: %s", mode)
+	}
+}
+
+// Event 是 CloudEvents 1.0 的 JSON 表示，Data 为原始的事件 payload。
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// FromOutbox 由 outbox 消息构造信封，subject 为聚合 ID，time 为事件写入 outbox 的时间。
+func FromOutbox(source string, message mysql.OutboxMessage) Event {
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              message.ID,
+		Source:          source,
+		Type:            message.EventType,
+		Subject:         message.AggregateID,
+		Time:            message.CreatedAt.UTC(),
+		DataContentType: ContentTypeJSON,
+		Data:            json.RawMessage(message.Payload),
+	}
+}
+
+// Encode 按内容模式返回 HTTP 请求头与请求体。
+func (e Event) Encode(mode Mode) (http.Header, []byte, error) {
+	header := http.Header{}
+	switch mode {
+	case ModeStructured:
+		body, err := json.Marshal(e)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal cloudevent: %w", err)
+		}
+		header.Set("Content-Type", ContentTypeCloudEvent)
+		return header, body, nil
+	case ModeBinary:
+		header.Set("Content-Type", e.DataContentType)
+		header.Set(HeaderSpecVersion, e.SpecVersion)
+		header.Set(HeaderID, e.ID)
+		header.Set(HeaderSource, e.Source)
+		header.Set(HeaderType, e.Type)
+		if e.Subject != "" {
+			header.Set(HeaderSubject, e.Subject)
+		}
+		header.Set(HeaderTime, e.Time.Format(time.RFC3339Nano))
+		return header, e.Data, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported cloudevents mode: %s", mode)
+	}
+}
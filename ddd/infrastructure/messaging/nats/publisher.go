@@ -0,0 +1,183 @@
+/*
+Package nats 提供基于 NATS JetStream 的 outbox 事件发布器。
+
+事件按类型映射到 subject，并以 outbox 事件 ID 作为 Nats-Msg-Id，
+JetStream 在流的 Duplicates 窗口内据此去重，worker 重试或重放不会产生重复消息。
+每次发布都会等待 PubAck，确认消息已持久化到流后才返回，之后 worker 才标记 PUBLISHED。
+*/
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ddd/infrastructure/messaging"
+	"ddd/infrastructure/messaging/cloudevents"
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/pkg/logger"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderEventType    = "Outbox-Event-Type"
+	HeaderEventVersion = "Outbox-Event-Version"
+
+	DefaultSubjectPrefix = "outbox."
+	DefaultAckTimeout    = 5 * time.Second
+)
+
+// SubjectMapping 将匹配 Pattern 的事件类型发布到 Subject。
+// Pattern 支持精确匹配、前缀通配（如 "order.*"）以及 "*"。
+type SubjectMapping struct {
+	Pattern string
+	Subject string
+}
+
+type Config struct {
+	URL string
+	// Subjects 按顺序匹配，均不匹配时发布到 SubjectPrefix + 事件类型。
+	Subjects      []SubjectMapping
+	SubjectPrefix string
+	AckTimeout    time.Duration
+	// Envelope 为空时消息体为原始 payload；structured 时为完整的 CloudEvents JSON，
+	// binary 时上下文属性写入 ce-* 消息头。
+	Envelope cloudevents.Mode
+	Source   string
+}
+
+type Publisher struct {
+	conn          *natsgo.Conn
+	js            jetstream.JetStream
+	subjects      []SubjectMapping
+	subjectPrefix string
+	ackTimeout    time.Duration
+	envelope      cloudevents.Mode
+	source        string
+}
+
+// NewPublisher 连接 NATS 并创建发布器，断线后自动重连。
+func NewPublisher(config Config) (*Publisher, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("nats url is required")
+	}
+	conn, err := natsgo.Connect(config.URL,
+		natsgo.Name("ddd-outbox-publisher"),
+		natsgo.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	publisher, err := NewPublisherWithConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return publisher, nil
+}
+
+// NewPublisherWithConn 复用已有连接创建发布器。
+func NewPublisherWithConn(conn *natsgo.Conn, config Config) (*Publisher, error) {
+	for _, mapping := range config.Subjects {
+		if mapping.Pattern == "" || mapping.Subject == "" {
+			return nil, fmt.Errorf("nats subject mapping requires both pattern and subject")
+		}
+	}
+	if config.Envelope != "" {
+		if _, err := cloudevents.ParseMode(string(config.Envelope)); err != nil {
+			return nil, err
+		}
+		if config.Source == "" {
+			return nil, fmt.Errorf("cloudevents source is required")
+		}
+	}
+	if config.SubjectPrefix == "" {
+		config.SubjectPrefix = DefaultSubjectPrefix
+	}
+	if config.AckTimeout <= 0 {
+		config.AckTimeout = DefaultAckTimeout
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	return &Publisher{
+		conn:          conn,
+		js:            js,
+		subjects:      config.Subjects,
+		subjectPrefix: config.SubjectPrefix,
+		ackTimeout:    config.AckTimeout,
+		envelope:      config.Envelope,
+		source:        config.Source,
+	}, nil
+}
+
+// Publish 发布消息并等待 PubAck，未收到确认时返回错误以触发重试。
+func (p *Publisher) Publish(ctx context.Context, message mysql.OutboxMessage) error {
+	msg, err := p.buildMsg(message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.ackTimeout)
+	defer cancel()
+
+	ack, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(message.ID))
+	if err != nil {
+		return fmt.Errorf("failed to publish event %s to %s: %w", message.ID, msg.Subject, err)
+	}
+	if ack.Duplicate {
+		logger.Debug("JetStream detected duplicate outbox event",
+			zap.String("event_id", message.ID),
+			zap.String("stream", ack.Stream),
+			zap.Uint64("sequence", ack.Sequence),
+		)
+	}
+	return nil
+}
+
+func (p *Publisher) buildMsg(message mysql.OutboxMessage) (*natsgo.Msg, error) {
+	msg := natsgo.NewMsg(p.Subject(message.EventType))
+	msg.Header.Set(HeaderEventType, message.EventType)
+	msg.Header.Set(HeaderEventVersion, fmt.Sprint(message.EventVersion))
+
+	if p.envelope == "" {
+		msg.Data = []byte(message.Payload)
+		return msg, nil
+	}
+
+	header, body, err := cloudevents.FromOutbox(p.source, message).Encode(p.envelope)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			msg.Header.Add(key, value)
+		}
+	}
+	msg.Data = body
+	return msg, nil
+}
+
+// Subject 返回事件类型对应的 subject。
+func (p *Publisher) Subject(eventType string) string {
+	for _, mapping := range p.subjects {
+		if messaging.MatchPattern(mapping.Pattern, eventType) {
+			return mapping.Subject
+		}
+	}
+	return p.subjectPrefix + eventType
+}
+
+// Close 在关闭连接前等待已发出的消息写完。
+func (p *Publisher) Close() error {
+	return p.conn.Drain()
+}
+
+var _ mysql.OutboxPublisher = (*Publisher)(nil)
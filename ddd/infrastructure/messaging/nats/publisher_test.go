@@ -0,0 +1,176 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ddd/infrastructure/messaging/cloudevents"
+	"ddd/infrastructure/persistence/mysql"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func runJetStreamServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func setupStream(t *testing.T, url string, subjects ...string) (jetstream.JetStream, jetstream.Stream) {
+	t.Helper()
+	conn, err := natsgo.Connect(url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("failed to create jetstream context: %v", err)
+	}
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:       "OUTBOX",
+		Subjects:   subjects,
+		Duplicates: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("failed to create stream: %v", err)
+	}
+	return js, stream
+}
+
+func message(id, eventType string) mysql.OutboxMessage {
+	return mysql.OutboxMessage{
+		ID:           id,
+		AggregateID:  "order-1",
+		EventType:    eventType,
+		EventVersion: 1,
+		Payload:      `{"order_id":"order-1"}`,
+		CreatedAt:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestPublisherRoutesAndDeduplicates(t *testing.T) {
+	srv := runJetStreamServer(t)
+	_, stream := setupStream(t, srv.ClientURL(), "orders.>", "outbox.>")
+
+	publisher, err := NewPublisher(Config{
+		URL:      srv.ClientURL(),
+		Subjects: []SubjectMapping{{Pattern: "order.*", Subject: "orders.events"}},
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+
+	ctx := context.Background()
+	for _, msg := range []mysql.OutboxMessage{
+		message("evt-1", "order.placed"),
+		message("evt-1", "order.placed"), // worker 重试时重复发布
+		message("evt-2", "user.created"),
+	} {
+		if err := publisher.Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish(%s) error = %v", msg.ID, err)
+		}
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("stream.Info() error = %v", err)
+	}
+	if info.State.Msgs != 2 {
+		t.Fatalf("stream messages = %d, want 2 after dedupe", info.State.Msgs)
+	}
+
+	testCases := []struct {
+		seq     uint64
+		subject string
+		msgID   string
+	}{
+		{1, "orders.events", "evt-1"},
+		{2, "outbox.user.created", "evt-2"},
+	}
+	for _, tc := range testCases {
+		stored, err := stream.GetMsg(ctx, tc.seq)
+		if err != nil {
+			t.Fatalf("GetMsg(%d) error = %v", tc.seq, err)
+		}
+		if stored.Subject != tc.subject {
+			t.Errorf("subject = %s, want %s", stored.Subject, tc.subject)
+		}
+		if got := stored.Header.Get(jetstream.MsgIDHeader); got != tc.msgID {
+			t.Errorf("%s = %s, want %s", jetstream.MsgIDHeader, got, tc.msgID)
+		}
+		if string(stored.Data) != `{"order_id":"order-1"}` {
+			t.Errorf("data = %s, want raw payload", stored.Data)
+		}
+	}
+}
+
+func TestPublisherStructuredCloudEvent(t *testing.T) {
+	srv := runJetStreamServer(t)
+	_, stream := setupStream(t, srv.ClientURL(), "outbox.>")
+
+	publisher, err := NewPublisher(Config{
+		URL:      srv.ClientURL(),
+		Envelope: cloudevents.ModeStructured,
+		Source:   "/ddd",
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+
+	ctx := context.Background()
+	if err := publisher.Publish(ctx, message("evt-1", "order.placed")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	stored, err := stream.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetMsg() error = %v", err)
+	}
+	var event cloudevents.Event
+	if err := json.Unmarshal(stored.Data, &event); err != nil {
+		t.Fatalf("data is not a cloudevent: %v", err)
+	}
+	if event.ID != "evt-1" || event.Type != "order.placed" || event.Subject != "order-1" {
+		t.Errorf("event = %+v, want id/type/subject from outbox message", event)
+	}
+}
+
+func TestPublisherFailsWithoutStream(t *testing.T) {
+	srv := runJetStreamServer(t)
+
+	publisher, err := NewPublisher(Config{
+		URL:        srv.ClientURL(),
+		AckTimeout: 500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+
+	if err := publisher.Publish(context.Background(), message("evt-1", "order.placed")); err == nil {
+		t.Fatal("Publish() error = nil, want error when no stream acknowledges the subject")
+	}
+}
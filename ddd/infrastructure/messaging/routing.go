@@ -0,0 +1,10 @@
+// Package messaging 存放各消息发布器共用的路由工具，具体传输实现位于子包中。
+package messaging
+
+import "ddd/domain/shared"
+
+// MatchPattern 判断事件类型是否匹配模式："*" 匹配全部，"order.*" 匹配 "order." 前缀。
+// 与进程内 EventBus 的订阅模式语义一致。
+func MatchPattern(pattern, eventType string) bool {
+	return shared.MatchEventName(pattern, eventType)
+}
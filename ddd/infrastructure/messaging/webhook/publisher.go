@@ -0,0 +1,192 @@
+/*
+Package webhook 提供基于 HTTP 回调的 outbox 事件发布器。
+
+每个事件按事件类型路由到配置的端点，请求体使用 HMAC-SHA256 签名：
+
+	X-Webhook-Timestamp: <unix 秒>
+	X-Webhook-Signature: sha256=<hex(HMAC(secret, timestamp + "." + body))>
+
+接收方应校验签名并拒绝时间戳偏差过大的请求以防重放。
+
+配置 Envelope 后请求按 CloudEvents 1.0 的 structured 或 binary 模式编码，
+签名覆盖实际发送的请求体。
+*/
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ddd/infrastructure/messaging"
+	"ddd/infrastructure/messaging/cloudevents"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+const (
+	HeaderEventID   = "X-Outbox-Event-ID"
+	HeaderEventType = "X-Outbox-Event-Type"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	DefaultTimeout = 5 * time.Second
+
+	maxErrorBodyBytes = 512
+)
+
+// Endpoint 描述一个事件类型模式对应的投递地址。
+// Pattern 支持精确匹配、前缀通配（如 "order.*"）以及 "*"。
+type Endpoint struct {
+	Pattern string
+	URL     string
+	// Secret 为空时使用 Config.Secret。
+	Secret string
+}
+
+type Config struct {
+	Endpoints []Endpoint
+	Secret    string
+	Timeout   time.Duration
+	// Envelope 为空时直接发送 payload，否则按 CloudEvents 的对应模式封装。
+	Envelope cloudevents.Mode
+	// Source 为 CloudEvents 的 source 属性，启用 Envelope 时必填。
+	Source string
+}
+
+// DeliveryError 表示端点返回了非 2xx 响应。
+type DeliveryError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("webhook %s responded with status %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+type Publisher struct {
+	endpoints []Endpoint
+	secret    string
+	envelope  cloudevents.Mode
+	source    string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewPublisher(config Config) (*Publisher, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one webhook endpoint is required")
+	}
+	for _, endpoint := range config.Endpoints {
+		if endpoint.Pattern == "" || endpoint.URL == "" {
+			return nil, fmt.Errorf("webhook endpoint requires both pattern and url")
+		}
+		if endpoint.Secret == "" && config.Secret == "" {
+			return nil, fmt.Errorf("webhook endpoint %s has no signing secret", endpoint.URL)
+		}
+	}
+	if config.Envelope != "" {
+		if _, err := cloudevents.ParseMode(string(config.Envelope)); err != nil {
+			return nil, err
+		}
+		if config.Source == "" {
+			return nil, fmt.Errorf("cloudevents source is required")
+		}
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Publisher{
+		endpoints: config.Endpoints,
+		secret:    config.Secret,
+		envelope:  config.Envelope,
+		source:    config.Source,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+	}, nil
+}
+
+func (p *Publisher) Publish(ctx context.Context, message mysql.OutboxMessage) error {
+	endpoint, ok := p.route(message.EventType)
+	if !ok {
+		return fmt.Errorf("no webhook endpoint configured for event type %s", message.EventType)
+	}
+
+	header, body, err := p.encode(message)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(p.now().Unix(), 10)
+	secret := endpoint.Secret
+	if secret == "" {
+		secret = p.secret
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header = header
+	req.Header.Set(HeaderEventID, message.ID)
+	req.Header.Set(HeaderEventType, message.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request to %s failed: %w", endpoint.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &DeliveryError{
+			URL:        endpoint.URL,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(snippet)),
+		}
+	}
+	// 读尽响应体以便复用连接。
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (p *Publisher) encode(message mysql.OutboxMessage) (http.Header, []byte, error) {
+	if p.envelope == "" {
+		header := http.Header{}
+		header.Set("Content-Type", cloudevents.ContentTypeJSON)
+		return header, []byte(message.Payload), nil
+	}
+	return cloudevents.FromOutbox(p.source, message).Encode(p.envelope)
+}
+
+// route 按配置顺序返回第一个匹配的端点。
+func (p *Publisher) route(eventType string) (Endpoint, bool) {
+	for _, endpoint := range p.endpoints {
+		if messaging.MatchPattern(endpoint.Pattern, eventType) {
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// Sign 计算 timestamp + "." + body 的 HMAC-SHA256 十六进制签名。
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var _ mysql.OutboxPublisher = (*Publisher)(nil)
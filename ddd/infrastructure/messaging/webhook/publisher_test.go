@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ddd/infrastructure/messaging/cloudevents"
+	"ddd/infrastructure/persistence/mysql"
+)
+
+func message(eventType string) mysql.OutboxMessage {
+	return mysql.OutboxMessage{
+		ID:          "0190a1b2-0000-7000-8000-000000000001",
+		AggregateID: "order-1",
+		EventType:   eventType,
+		Payload:     `{"order_id":"order-1"}`,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestPublisherSignsAndRoutesByEventType(t *testing.T) {
+	type received struct {
+		path      string
+		eventType string
+		timestamp string
+		signature string
+		body      string
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{
+			path:      r.URL.Path,
+			eventType: r.Header.Get(HeaderEventType),
+			timestamp: r.Header.Get(HeaderTimestamp),
+			signature: r.Header.Get(HeaderSignature),
+			body:      string(body),
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher, err := NewPublisher(Config{
+		Endpoints: []Endpoint{
+			{Pattern: "order.*", URL: server.URL + "/fulfilment", Secret: "order-secret"},
+			{Pattern: "*", URL: server.URL + "/catch-all"},
+		},
+		Secret: "default-secret",
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	publisher.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	testCases := []struct {
+		eventType string
+		path      string
+		secret    string
+	}{
+		{"order.placed", "/fulfilment", "order-secret"},
+		{"user.created", "/catch-all", "default-secret"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.eventType, func(t *testing.T) {
+			payload := `{"aggregate_id":"a-1"}`
+			if err := publisher.Publish(context.Background(), mysql.OutboxMessage{
+				ID:        "evt-1",
+				EventType: tc.eventType,
+				Payload:   payload,
+			}); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			got := <-requests
+			if got.path != tc.path {
+				t.Errorf("path = %s, want %s", got.path, tc.path)
+			}
+			if got.eventType != tc.eventType {
+				t.Errorf("event type header = %s, want %s", got.eventType, tc.eventType)
+			}
+			if got.timestamp != "1700000000" {
+				t.Errorf("timestamp header = %s, want 1700000000", got.timestamp)
+			}
+			want := "sha256=" + Sign(tc.secret, got.timestamp, []byte(payload))
+			if got.signature != want {
+				t.Errorf("signature = %s, want %s", got.signature, want)
+			}
+			if got.body != payload {
+				t.Errorf("body = %s, want %s", got.body, payload)
+			}
+		})
+	}
+}
+
+func TestPublisherMapsErrorResponses(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusInternalServerError} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "downstream rejected", status)
+		}))
+
+		publisher, err := NewPublisher(Config{
+			Endpoints: []Endpoint{{Pattern: "*", URL: server.URL}},
+			Secret:    "secret",
+		})
+		if err != nil {
+			t.Fatalf("NewPublisher() error = %v", err)
+		}
+
+		err = publisher.Publish(context.Background(), message("order.placed"))
+		var deliveryErr *DeliveryError
+		if !errors.As(err, &deliveryErr) {
+			t.Fatalf("Publish() error = %v, want *DeliveryError", err)
+		}
+		if deliveryErr.StatusCode != status {
+			t.Errorf("status = %d, want %d", deliveryErr.StatusCode, status)
+		}
+		server.Close()
+	}
+}
+
+func TestPublisherHonoursTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	publisher, err := NewPublisher(Config{
+		Endpoints: []Endpoint{{Pattern: "*", URL: server.URL}},
+		Secret:    "secret",
+		Timeout:   50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+
+	if err := publisher.Publish(context.Background(), message("order.placed")); err == nil {
+		t.Fatal("Publish() error = nil, want timeout error")
+	}
+}
+
+func TestPublisherRejectsUnroutedEvents(t *testing.T) {
+	publisher, err := NewPublisher(Config{
+		Endpoints: []Endpoint{{Pattern: "order.*", URL: "http://127.0.0.1:0"}},
+		Secret:    "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+
+	if err := publisher.Publish(context.Background(), message("user.created")); err == nil {
+		t.Fatal("Publish() error = nil, want routing error")
+	}
+}
+
+func TestPublisherCloudEventsEnvelope(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	newPublisher := func(mode cloudevents.Mode) *Publisher {
+		publisher, err := NewPublisher(Config{
+			Endpoints: []Endpoint{{Pattern: "*", URL: server.URL}},
+			Secret:    "secret",
+			Envelope:  mode,
+			Source:    "/ddd/orders",
+		})
+		if err != nil {
+			t.Fatalf("NewPublisher() error = %v", err)
+		}
+		return publisher
+	}
+	msg := message("order.placed")
+
+	t.Run("structured", func(t *testing.T) {
+		if err := newPublisher(cloudevents.ModeStructured).Publish(context.Background(), msg); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		req, body := <-requests, <-bodies
+		if got := req.Header.Get("Content-Type"); got != cloudevents.ContentTypeCloudEvent {
+			t.Errorf("Content-Type = %s, want %s", got, cloudevents.ContentTypeCloudEvent)
+		}
+
+		var event map[string]interface{}
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("body is not JSON: %v", err)
+		}
+		want := map[string]string{
+			"specversion":     "1.0",
+			"id":              msg.ID,
+			"source":          "/ddd/orders",
+			"type":            "order.placed",
+			"subject":         "order-1",
+			"time":            "2024-01-02T03:04:05Z",
+			"datacontenttype": "application/json",
+		}
+		for key, value := range want {
+			if event[key] != value {
+				t.Errorf("%s = %v, want %s", key, event[key], value)
+			}
+		}
+		data, _ := event["data"].(map[string]interface{})
+		if data["order_id"] != "order-1" {
+			t.Errorf("data = %v, want payload object", event["data"])
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		if err := newPublisher(cloudevents.ModeBinary).Publish(context.Background(), msg); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		req, body := <-requests, <-bodies
+		want := map[string]string{
+			"Content-Type":                "application/json",
+			cloudevents.HeaderSpecVersion: "1.0",
+			cloudevents.HeaderID:          msg.ID,
+			cloudevents.HeaderSource:      "/ddd/orders",
+			cloudevents.HeaderType:        "order.placed",
+			cloudevents.HeaderSubject:     "order-1",
+			cloudevents.HeaderTime:        "2024-01-02T03:04:05Z",
+		}
+		for key, value := range want {
+			if got := req.Header.Get(key); got != value {
+				t.Errorf("%s = %s, want %s", key, got, value)
+			}
+		}
+		if string(body) != msg.Payload {
+			t.Errorf("body = %s, want %s", body, msg.Payload)
+		}
+		wantSignature := "sha256=" + Sign("secret", req.Header.Get(HeaderTimestamp), body)
+		if got := req.Header.Get(HeaderSignature); got != wantSignature {
+			t.Errorf("signature = %s, want %s", got, wantSignature)
+		}
+	})
+}
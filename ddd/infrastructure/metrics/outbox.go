@@ -0,0 +1,200 @@
+/*
+Package metrics 导出 outbox 的积压与投递指标。
+
+积压类指标（待发布数量、最早待发布事件的等待时长、死信数量）在每次采集时查询数据库，
+多个副本看到的是同一张表；投递类指标（发布延迟直方图、按事件类型的失败次数）由本进程内的
+worker 上报，未运行 worker 的进程中始终为零。
+*/
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"ddd/infrastructure/persistence/mysql"
+	"ddd/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	namespace = "ddd"
+	subsystem = "outbox"
+
+	// DefaultStatsTimeout 限制单次采集查询数据库的时长，避免慢查询拖住 Prometheus 抓取。
+	DefaultStatsTimeout = 5 * time.Second
+)
+
+// OutboxStatsSource 提供 outbox 表的积压快照，由 mysql.OutboxRepository 实现。
+type OutboxStatsSource interface {
+	Stats(ctx context.Context) (*mysql.OutboxStats, error)
+}
+
+// OutboxSnapshot 是健康检查接口展示的 outbox 指标，与 Prometheus 导出的数值同源。
+type OutboxSnapshot struct {
+	Pending             int64            `json:"pending"`
+	Processing          int64            `json:"processing"`
+	DeadLetters         int64            `json:"dead_letters"`
+	OldestPendingAge    string           `json:"oldest_pending_age,omitempty"`
+	Published           int64            `json:"published"`
+	AvgPublishLatency   string           `json:"avg_publish_latency,omitempty"`
+	FailuresByEventType map[string]int64 `json:"failures_by_event_type,omitempty"`
+}
+
+// OutboxMetrics 实现 prometheus.Collector 与 mysql.OutboxMetrics。
+type OutboxMetrics struct {
+	source OutboxStatsSource
+
+	publishLatency *prometheus.HistogramVec
+	published      *prometheus.CounterVec
+	failures       *prometheus.CounterVec
+
+	pendingDesc       *prometheus.Desc
+	processingDesc    *prometheus.Desc
+	oldestPendingDesc *prometheus.Desc
+	deadLettersDesc   *prometheus.Desc
+
+	mu             sync.Mutex
+	publishedTotal int64
+	latencyTotal   time.Duration
+	failuresByType map[string]int64
+}
+
+func NewOutboxMetrics(source OutboxStatsSource) *OutboxMetrics {
+	return &OutboxMetrics{
+		source: source,
+		publishLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "publish_duration_seconds",
+			Help:      "Latency of publishing a single outbox event, including failed attempts.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"event_type"}),
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "published_total",
+			Help:      "Outbox events published successfully by this process.",
+		}, []string{"event_type"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "publish_failures_total",
+			Help:      "Failed outbox publish attempts by this process.",
+		}, []string{"event_type"}),
+		pendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "pending_events"),
+			"Outbox events waiting to be claimed.", nil, nil,
+		),
+		processingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "processing_events"),
+			"Outbox events currently leased by a worker.", nil, nil,
+		),
+		oldestPendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "oldest_pending_age_seconds"),
+			"Age of the oldest unpublished outbox event, 0 when there is no backlog.", nil, nil,
+		),
+		deadLettersDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "dead_letter_events"),
+			"Outbox events in FAILED status waiting for manual replay or discard.", nil, nil,
+		),
+		failuresByType: make(map[string]int64),
+	}
+}
+
+// ObservePublish 记录一次投递的耗时与结果，由 OutboxWorker 调用。
+func (m *OutboxMetrics) ObservePublish(eventType string, latency time.Duration, err error) {
+	m.publishLatency.WithLabelValues(eventType).Observe(latency.Seconds())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failures.WithLabelValues(eventType).Inc()
+		m.failuresByType[eventType]++
+		return
+	}
+	m.published.WithLabelValues(eventType).Inc()
+	m.publishedTotal++
+	m.latencyTotal += latency
+}
+
+func (m *OutboxMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.publishLatency.Describe(ch)
+	m.published.Describe(ch)
+	m.failures.Describe(ch)
+	ch <- m.pendingDesc
+	ch <- m.processingDesc
+	ch <- m.oldestPendingDesc
+	ch <- m.deadLettersDesc
+}
+
+// Collect 在抓取时查询积压快照；查询失败时只导出投递类指标并为积压类指标报告错误。
+func (m *OutboxMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.publishLatency.Collect(ch)
+	m.published.Collect(ch)
+	m.failures.Collect(ch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultStatsTimeout)
+	defer cancel()
+	stats, err := m.source.Stats(ctx)
+	if err != nil {
+		logger.Warn("Failed to collect outbox stats", zap.Error(err))
+		for _, desc := range []*prometheus.Desc{m.pendingDesc, m.processingDesc, m.oldestPendingDesc, m.deadLettersDesc} {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+		}
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(m.pendingDesc, prometheus.GaugeValue, float64(stats.Pending))
+	ch <- prometheus.MustNewConstMetric(m.processingDesc, prometheus.GaugeValue, float64(stats.Processing))
+	ch <- prometheus.MustNewConstMetric(m.oldestPendingDesc, prometheus.GaugeValue, oldestPendingAge(stats).Seconds())
+	ch <- prometheus.MustNewConstMetric(m.deadLettersDesc, prometheus.GaugeValue, float64(stats.DeadLetters))
+}
+
+// Snapshot 返回健康检查使用的指标快照。
+func (m *OutboxMetrics) Snapshot(ctx context.Context) (*OutboxSnapshot, error) {
+	stats, err := m.source.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &OutboxSnapshot{
+		Pending:     stats.Pending,
+		Processing:  stats.Processing,
+		DeadLetters: stats.DeadLetters,
+	}
+	if stats.OldestPendingAt != nil {
+		snapshot.OldestPendingAge = oldestPendingAge(stats).Round(time.Millisecond).String()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot.Published = m.publishedTotal
+	if m.publishedTotal > 0 {
+		snapshot.AvgPublishLatency = (m.latencyTotal / time.Duration(m.publishedTotal)).String()
+	}
+	if len(m.failuresByType) > 0 {
+		snapshot.FailuresByEventType = make(map[string]int64, len(m.failuresByType))
+		for eventType, count := range m.failuresByType {
+			snapshot.FailuresByEventType[eventType] = count
+		}
+	}
+	return snapshot, nil
+}
+
+func oldestPendingAge(stats *mysql.OutboxStats) time.Duration {
+	if stats.OldestPendingAt == nil {
+		return 0
+	}
+	if age := time.Since(*stats.OldestPendingAt); age > 0 {
+		return age
+	}
+	return 0
+}
+
+var (
+	_ prometheus.Collector = (*OutboxMetrics)(nil)
+	_ mysql.OutboxMetrics  = (*OutboxMetrics)(nil)
+)
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ddd/infrastructure/persistence/mysql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubStats struct {
+	stats *mysql.OutboxStats
+	err   error
+}
+
+func (s *stubStats) Stats(context.Context) (*mysql.OutboxStats, error) {
+	return s.stats, s.err
+}
+
+func TestOutboxMetricsCollectsBacklog(t *testing.T) {
+	oldest := time.Now().Add(-90 * time.Second)
+	m := NewOutboxMetrics(&stubStats{stats: &mysql.OutboxStats{
+		Pending:         3,
+		Processing:      1,
+		DeadLetters:     2,
+		OldestPendingAt: &oldest,
+	}})
+
+	expected := `
+# HELP ddd_outbox_dead_letter_events Outbox events in FAILED status waiting for manual replay or discard.
+# TYPE ddd_outbox_dead_letter_events gauge
+ddd_outbox_dead_letter_events 2
+# HELP ddd_outbox_pending_events Outbox events waiting to be claimed.
+# TYPE ddd_outbox_pending_events gauge
+ddd_outbox_pending_events 3
+# HELP ddd_outbox_processing_events Outbox events currently leased by a worker.
+# TYPE ddd_outbox_processing_events gauge
+ddd_outbox_processing_events 1
+`
+	err := testutil.CollectAndCompare(m, strings.NewReader(expected),
+		"ddd_outbox_pending_events", "ddd_outbox_processing_events", "ddd_outbox_dead_letter_events")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxMetricsObservePublish(t *testing.T) {
+	m := NewOutboxMetrics(&stubStats{stats: &mysql.OutboxStats{}})
+	m.ObservePublish("order.placed", 20*time.Millisecond, nil)
+	m.ObservePublish("order.placed", 40*time.Millisecond, nil)
+	m.ObservePublish("order.placed", time.Millisecond, errors.New("boom"))
+	m.ObservePublish("user.created", time.Millisecond, errors.New("boom"))
+
+	if got := testutil.ToFloat64(m.failures.WithLabelValues("order.placed")); got != 1 {
+		t.Errorf("order.placed failures = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.published.WithLabelValues("order.placed")); got != 2 {
+		t.Errorf("order.placed published = %v, want 2", got)
+	}
+
+	snapshot, err := m.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if snapshot.Published != 2 || snapshot.AvgPublishLatency != "30ms" {
+		t.Errorf("snapshot published = %d, avg = %s; want 2, 30ms", snapshot.Published, snapshot.AvgPublishLatency)
+	}
+	if snapshot.FailuresByEventType["order.placed"] != 1 || snapshot.FailuresByEventType["user.created"] != 1 {
+		t.Errorf("failures by event type = %v", snapshot.FailuresByEventType)
+	}
+	if snapshot.OldestPendingAge != "" {
+		t.Errorf("oldest pending age = %s, want empty without backlog", snapshot.OldestPendingAge)
+	}
+}
+
+func TestOutboxMetricsReportsStatsError(t *testing.T) {
+	m := NewOutboxMetrics(&stubStats{err: errors.New("db down")})
+	if _, err := m.Snapshot(context.Background()); err == nil {
+		t.Fatal("Snapshot() error = nil, want stats error")
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(m)
+	if _, err := registry.Gather(); err == nil {
+		t.Fatal("Gather() error = nil, want invalid metric error")
+	}
+}
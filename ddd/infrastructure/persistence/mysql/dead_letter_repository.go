@@ -0,0 +1,152 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultDeadLetterPageSize = 50
+
+type DeadLetterRepository struct {
+	db *gorm.DB
+}
+
+func NewDeadLetterRepository(db *gorm.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+func (r *DeadLetterRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+func (r *DeadLetterRepository) failedEventsQuery(ctx context.Context, filter shared.DeadLetterFilter) *gorm.DB {
+	db := r.getDB(ctx).Model(&po.OutboxEventPO{}).
+		Where("status = ?", string(po.EventStatusFailed))
+	if filter.EventType != "" {
+		db = db.Where("event_type = ?", filter.EventType)
+	}
+	if filter.AggregateID != "" {
+		db = db.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	return db
+}
+func (r *DeadLetterRepository) ListFailedEvents(ctx context.Context, filter shared.DeadLetterFilter) ([]*shared.DeadLetterEvent, int64, error) {
+	var total int64
+	if err := r.failedEventsQuery(ctx, filter).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead letter events: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeadLetterPageSize
+	}
+	var eventPOs []*po.OutboxEventPO
+	err := r.failedEventsQuery(ctx, filter).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(filter.Offset).
+		Find(&eventPOs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead letter events: %w", err)
+	}
+
+	events := make([]*shared.DeadLetterEvent, len(eventPOs))
+	for i, eventPO := range eventPOs {
+		events[i] = eventPO.ToDeadLetter()
+	}
+	return events, total, nil
+}
+func (r *DeadLetterRepository) GetFailedEvent(ctx context.Context, eventID string) (*shared.DeadLetterEvent, error) {
+	var eventPO po.OutboxEventPO
+	err := r.getDB(ctx).
+		Where("id = ? AND status = ?", eventID, string(po.EventStatusFailed)).
+		First(&eventPO).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, shared.NewNotFoundError("dead letter event")
+		}
+		return nil, fmt.Errorf("failed to get dead letter event: %w", err)
+	}
+	return eventPO.ToDeadLetter(), nil
+}
+func (r *DeadLetterRepository) ReplayFailedEvents(ctx context.Context, eventIDs []string, audit shared.DeadLetterAudit) (int64, error) {
+	return r.transition(ctx, eventIDs, audit, po.AuditActionReplay, map[string]interface{}{
+		"status":           string(po.EventStatusPending),
+		"retry_count":      0,
+		"next_attempt_at":  nil,
+		"lease_owner":      "",
+		"lease_expires_at": nil,
+		"updated_at":       gorm.Expr("NOW()"),
+	})
+}
+func (r *DeadLetterRepository) DiscardFailedEvents(ctx context.Context, eventIDs []string, audit shared.DeadLetterAudit) (int64, error) {
+	return r.transition(ctx, eventIDs, audit, po.AuditActionDiscard, map[string]interface{}{
+		"status":     string(po.EventStatusDiscarded),
+		"updated_at": gorm.Expr("NOW()"),
+	})
+}
+
+// transition 在同一事务内锁定仍处于 FAILED 的事件、更新状态并写入审计记录。
+func (r *DeadLetterRepository) transition(
+	ctx context.Context,
+	eventIDs []string,
+	audit shared.DeadLetterAudit,
+	action string,
+	updates map[string]interface{},
+) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+
+	var affected int64
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var lockedIDs []string
+		err := tx.Model(&po.OutboxEventPO{}).
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("id IN ? AND status = ?", eventIDs, string(po.EventStatusFailed)).
+			Pluck("id", &lockedIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(lockedIDs) == 0 {
+			return nil
+		}
+
+		result := tx.Model(&po.OutboxEventPO{}).
+			Where("id IN ?", lockedIDs).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+
+		audits := make([]po.OutboxEventAuditPO, len(lockedIDs))
+		for i, eventID := range lockedIDs {
+			audits[i] = po.OutboxEventAuditPO{
+				ID:       uuid.Must(uuid.NewV7()).String(),
+				EventID:  eventID,
+				Action:   action,
+				Operator: audit.Operator,
+				Note:     audit.Note,
+			}
+		}
+		return tx.Create(&audits).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply %s to dead letter events: %w", action, err)
+	}
+
+	return affected, nil
+}
+
+var _ shared.DeadLetterRepository = (*DeadLetterRepository)(nil)
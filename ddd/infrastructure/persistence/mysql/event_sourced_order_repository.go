@@ -0,0 +1,232 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/retry"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OrderAggregateType 是订单事件流在事件存储中的聚合类型。
+const OrderAggregateType = "order"
+
+// EventSourcedOrderRepository 以事件存储为唯一数据源实现 order.Repository：
+// 保存时只追加聚合尚未持久化的事件，读取时重放事件流重建聚合，不读写 orders 表。
+//
+// 按用户查询借助下单事件 payload 中的 user_id 缩小范围；其他规约需要重放全部订单后
+// 在内存中过滤，只适合数据量较小的场景，读多的查询应改用投影。
+type EventSourcedOrderRepository struct {
+	db         *gorm.DB
+	store      *EventStore
+	snapshots  *SnapshotStore
+	uowFactory shared.UnitOfWorkFactory
+}
+
+func NewEventSourcedOrderRepository(db *gorm.DB) *EventSourcedOrderRepository {
+	return &EventSourcedOrderRepository{
+		db:         db,
+		store:      NewEventStore(db),
+		uowFactory: NewUnitOfWorkFactory(db, retry.DefaultConfig),
+	}
+}
+
+// SetSnapshotStore 启用快照：保存时按快照间隔写入快照，读取时从最近的有效快照开始重放。
+func (r *EventSourcedOrderRepository) SetSnapshotStore(snapshots *SnapshotStore) {
+	r.snapshots = snapshots
+}
+
+// SetUnitOfWorkFactory 设置 Remove 使用的工作单元，使取消事件按应用配置重试并在提交后进程内分发。
+func (r *EventSourcedOrderRepository) SetUnitOfWorkFactory(factory shared.UnitOfWorkFactory) {
+	r.uowFactory = factory
+}
+
+// Save 以聚合加载时的版本作为预期版本追加新事件，事件流已被其他事务推进时返回并发修改错误。
+func (r *EventSourcedOrderRepository) Save(ctx context.Context, o *order.Order) error {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return r.saveWithTx(ctx, o)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.saveWithTx(persistence.ContextWithTx(ctx, tx), o)
+	})
+}
+
+func (r *EventSourcedOrderRepository) saveWithTx(ctx context.Context, o *order.Order) error {
+	fromVersion := o.Version()
+	version, err := r.store.Append(ctx, OrderAggregateType, o.ID(), o.Version(), o.UnstoredEvents())
+	if err != nil {
+		if errors.Is(err, ErrEventStreamConflict) {
+			if o.IsNew() {
+				return fmt.Errorf("order %s already exists: %w", o.ID(), err)
+			}
+			return order.NewConcurrentModificationError(o.ID())
+		}
+		return err
+	}
+	o.MarkEventsStored(version)
+	o.ClearDirtyTracking()
+
+	if r.snapshots != nil && r.snapshots.Due(OrderAggregateType, fromVersion, version) {
+		r.saveSnapshot(ctx, o)
+	}
+	return nil
+}
+
+// saveSnapshot 与事件在同一事务内写入快照。快照只是读取优化，失败时记录日志而不影响保存。
+func (r *EventSourcedOrderRepository) saveSnapshot(ctx context.Context, o *order.Order) {
+	snapshot, err := newOrderSnapshot(o)
+	if err == nil {
+		err = r.snapshots.Save(ctx, snapshot)
+	}
+	if err != nil {
+		logger.Warn("Failed to save order snapshot",
+			zap.String("order_id", o.ID()),
+			zap.Int("version", o.Version()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (r *EventSourcedOrderRepository) FindByID(ctx context.Context, id string) (*order.Order, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	orders, err := r.rebuild(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, order.NewOrderNotFoundError(id)
+	}
+	return orders[0], nil
+}
+
+// FindAsOf 只重放发生于 at 及之前的事件。快照不记录时间点，这里总是完整重放。
+func (r *EventSourcedOrderRepository) FindAsOf(ctx context.Context, orderID string, at time.Time) (*order.Order, error) {
+	events, err := r.store.Load(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	o, err := order.RebuildFromEvents(eventsUntil(events, at))
+	if errors.Is(err, order.ErrOrderNotFound) {
+		return nil, order.NewOrderNotFoundError(orderID)
+	}
+	return o, err
+}
+
+func (r *EventSourcedOrderRepository) FindByUserID(ctx context.Context, userID string) ([]*order.Order, error) {
+	ids, err := r.store.AggregateIDs(ctx, OrderAggregateType,
+		"event_type = ? AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.user_id')) = ?", "order.placed", userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.rebuildMatching(ctx, ids, order.ByUserIDSpecification{UserID: userID})
+}
+
+func (r *EventSourcedOrderRepository) FindDeliveredOrdersByUserID(ctx context.Context, userID string) ([]*order.Order, error) {
+	orders, err := r.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	delivered := make([]*order.Order, 0, len(orders))
+	for _, o := range orders {
+		if o.Status() == order.StatusDelivered {
+			delivered = append(delivered, o)
+		}
+	}
+	return delivered, nil
+}
+
+func (r *EventSourcedOrderRepository) FindBySpecification(ctx context.Context, spec shared.Specification[*order.Order]) ([]*order.Order, error) {
+	ids, err := r.store.AggregateIDs(ctx, OrderAggregateType, "")
+	if err != nil {
+		return nil, err
+	}
+	return r.rebuildMatching(ctx, ids, spec)
+}
+
+// rebuildMatching 重建订单并按规约过滤，结果与状态存储仓储一致按创建时间倒序。
+func (r *EventSourcedOrderRepository) rebuildMatching(ctx context.Context, ids []string, spec shared.Specification[*order.Order]) ([]*order.Order, error) {
+	rebuilt, err := r.rebuild(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*order.Order, 0, len(rebuilt))
+	for _, o := range rebuilt {
+		if spec == nil || spec.IsSatisfiedBy(ctx, o) {
+			orders = append(orders, o)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt().After(orders[j].CreatedAt())
+	})
+	return orders, nil
+}
+
+// rebuild 按 ids 顺序重建订单，跳过不存在的事件流。有有效快照的订单从快照开始，
+// 只重放快照版本之后的事件；快照无法解码时记录日志并退回完整重放。
+func (r *EventSourcedOrderRepository) rebuild(ctx context.Context, ids []string) ([]*order.Order, error) {
+	snapshots := map[string]*Snapshot{}
+	if r.snapshots != nil {
+		var err error
+		snapshots, err = r.snapshots.LoadMany(ctx, OrderAggregateType, ids, OrderSnapshotSchemaVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	afterVersions := make(map[string]int, len(snapshots))
+	bases := make(map[string]*order.Order, len(snapshots))
+	for id, snapshot := range snapshots {
+		base, err := rebuildOrderFromSnapshot(snapshot)
+		if err != nil {
+			logger.Warn("Ignoring unreadable order snapshot", zap.String("order_id", id), zap.Error(err))
+			continue
+		}
+		bases[id] = base
+		afterVersions[id] = snapshot.Version
+	}
+
+	streams, err := r.store.LoadStreams(ctx, ids, afterVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*order.Order, 0, len(ids))
+	for _, id := range ids {
+		o, ok := bases[id]
+		if ok {
+			err = o.Replay(streams[id])
+		} else if len(streams[id]) > 0 {
+			o, err = order.RebuildFromEvents(streams[id])
+		} else {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild order %s: %w", id, err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// Remove 与状态存储仓储一致，将订单标记为已取消：取消事件追加到事件流，并经工作单元写入 outbox。
+func (r *EventSourcedOrderRepository) Remove(ctx context.Context, id string) error {
+	return removeOrder(ctx, r.uowFactory, r, id)
+}
+
+var (
+	_ order.Repository    = (*EventSourcedOrderRepository)(nil)
+	_ order.HistoryReader = (*EventSourcedOrderRepository)(nil)
+)
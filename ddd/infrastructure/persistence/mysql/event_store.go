@@ -0,0 +1,197 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/eventcodec"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrEventStreamConflict 表示追加时事件流的当前版本与预期不符，即有其他事务先行追加。
+var ErrEventStreamConflict = errors.New("event stream version conflict")
+
+// EventStore 是只追加的事件存储。每个聚合对应一条事件流，版本从 1 开始连续递增，
+// 追加时校验预期版本，并由 (aggregate_id, version) 唯一索引兜底并发写入。
+// payload 使用 eventcodec.Default 序列化，与 outbox 共用同一套 schema 版本与 upcaster。
+type EventStore struct {
+	db     *gorm.DB
+	codecs *eventcodec.Registry
+}
+
+func NewEventStore(db *gorm.DB) *EventStore {
+	return &EventStore{db: db, codecs: eventcodec.Default()}
+}
+
+func (s *EventStore) getDB(ctx context.Context) *gorm.DB {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return s.db.WithContext(ctx)
+}
+
+// Append 在事件流版本等于 expectedVersion 时追加事件，返回追加后的流版本。
+// 新建聚合的 expectedVersion 为 0；版本不符时返回 ErrEventStreamConflict。
+func (s *EventStore) Append(ctx context.Context, aggregateType, aggregateID string, expectedVersion int, events []shared.DomainEvent) (int, error) {
+	if len(events) == 0 {
+		return expectedVersion, nil
+	}
+	for _, event := range events {
+		if err := shared.ValidateEvent(event); err != nil {
+			return 0, fmt.Errorf("invalid domain event: %w", err)
+		}
+		if event.GetAggregateID() != aggregateID {
+			return 0, fmt.Errorf("event %s belongs to aggregate %s, not %s", event.EventName(), event.GetAggregateID(), aggregateID)
+		}
+	}
+
+	var version int
+	appendWithTx := func(tx *gorm.DB) error {
+		current, err := s.currentVersion(tx, aggregateID)
+		if err != nil {
+			return err
+		}
+		if current != expectedVersion {
+			return fmt.Errorf("%w: aggregate %s is at version %d, expected %d", ErrEventStreamConflict, aggregateID, current, expectedVersion)
+		}
+
+		rows := make([]po.StoredEventPO, len(events))
+		for i, event := range events {
+			encoded, err := s.codecs.Encode(event)
+			if err != nil {
+				return fmt.Errorf("failed to serialize domain event: %w", err)
+			}
+			eventID, err := uuid.NewV7()
+			if err != nil {
+				return fmt.Errorf("failed to generate event ID: %w", err)
+			}
+			rows[i] = po.StoredEventPO{
+				EventID:       eventID.String(),
+				AggregateID:   aggregateID,
+				AggregateType: aggregateType,
+				Version:       expectedVersion + i + 1,
+				EventType:     encoded.EventType,
+				EventVersion:  encoded.Version,
+				Payload:       string(encoded.Payload),
+				OccurredOn:    event.OccurredOn(),
+			}
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			if isDuplicateKeyError(err) {
+				return fmt.Errorf("%w: aggregate %s was appended concurrently", ErrEventStreamConflict, aggregateID)
+			}
+			return fmt.Errorf("failed to append events: %w", err)
+		}
+		version = expectedVersion + len(events)
+		return nil
+	}
+
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		if err := appendWithTx(tx); err != nil {
+			return 0, err
+		}
+		return version, nil
+	}
+	if err := s.db.WithContext(ctx).Transaction(appendWithTx); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (s *EventStore) currentVersion(tx *gorm.DB, aggregateID string) (int, error) {
+	var version int
+	err := tx.Model(&po.StoredEventPO{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("aggregate_id = ?", aggregateID).
+		Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load stream version: %w", err)
+	}
+	return version, nil
+}
+
+// Load 按版本顺序读取聚合的完整事件流，聚合不存在时返回空切片。
+func (s *EventStore) Load(ctx context.Context, aggregateID string) ([]shared.DomainEvent, error) {
+	return s.LoadAfter(ctx, aggregateID, 0)
+}
+
+// LoadAfter 读取版本大于 afterVersion 的事件。
+func (s *EventStore) LoadAfter(ctx context.Context, aggregateID string, afterVersion int) ([]shared.DomainEvent, error) {
+	var rows []po.StoredEventPO
+	err := s.getDB(ctx).
+		Where("aggregate_id = ? AND version > ?", aggregateID, afterVersion).
+		Order("version ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event stream: %w", err)
+	}
+	return s.decode(rows)
+}
+
+// AggregateIDs 按创建顺序返回指定类型的聚合 ID。query 非空时作为附加条件作用于
+// 各事件流版本为 1 的首个事件，例如按下单事件 payload 中的用户 ID 过滤。
+func (s *EventStore) AggregateIDs(ctx context.Context, aggregateType string, query string, args ...any) ([]string, error) {
+	db := s.getDB(ctx).Model(&po.StoredEventPO{}).
+		Where("aggregate_type = ? AND version = 1", aggregateType)
+	if query != "" {
+		db = db.Where(query, args...)
+	}
+	var ids []string
+	if err := db.Order("position ASC").Pluck("aggregate_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	return ids, nil
+}
+
+// LoadStreams 批量读取多个聚合的事件流，结果按聚合 ID 分组。
+// afterVersions 中有记录的聚合只返回版本大于该值的事件（如已有快照），为 nil 时返回完整事件流。
+func (s *EventStore) LoadStreams(ctx context.Context, aggregateIDs []string, afterVersions map[string]int) (map[string][]shared.DomainEvent, error) {
+	streams := make(map[string][]shared.DomainEvent, len(aggregateIDs))
+	if len(aggregateIDs) == 0 {
+		return streams, nil
+	}
+
+	var rows []po.StoredEventPO
+	err := s.getDB(ctx).
+		Where("aggregate_id IN ?", aggregateIDs).
+		Order("aggregate_id ASC, version ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event streams: %w", err)
+	}
+	if afterVersions != nil {
+		newer := rows[:0]
+		for _, row := range rows {
+			if row.Version > afterVersions[row.AggregateID] {
+				newer = append(newer, row)
+			}
+		}
+		rows = newer
+	}
+	events, err := s.decode(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		streams[row.AggregateID] = append(streams[row.AggregateID], events[i])
+	}
+	return streams, nil
+}
+
+func (s *EventStore) decode(rows []po.StoredEventPO) ([]shared.DomainEvent, error) {
+	events := make([]shared.DomainEvent, len(rows))
+	for i, row := range rows {
+		event, err := s.codecs.Decode(row.EventType, row.EventVersion, []byte(row.Payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event %s v%d of aggregate %s: %w", row.EventType, row.Version, row.AggregateID, err)
+		}
+		events[i] = event
+	}
+	return events, nil
+}
@@ -0,0 +1,134 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/eventcodec"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errEventAlreadyProcessed 区分处理记录冲突与处理器自身写入时的唯一键冲突。
+var errEventAlreadyProcessed = errors.New("event already processed")
+
+// InboxDispatcher 将 outbox 事件解码后分发给进程内的处理器，并借助 processed_events
+// 实现幂等：每个处理器（以 Name() 作为消费者名）在独立事务中先写入处理记录，再执行处理逻辑，
+// 两者一起提交或回滚。重复投递的事件会因主键冲突被跳过。
+//
+// 实现 shared.ContextEventHandler 的处理器会收到携带事务的 ctx，其写操作与处理记录原子提交；
+// 仅实现 shared.EventHandler 的处理器无法参与事务，只能保证处理成功后不再重复调用。
+type InboxDispatcher struct {
+	db       *gorm.DB
+	codecs   *eventcodec.Registry
+	mu       sync.RWMutex
+	handlers map[string][]shared.EventHandler
+}
+
+func NewInboxDispatcher(db *gorm.DB) *InboxDispatcher {
+	return &InboxDispatcher{
+		db:       db,
+		codecs:   eventcodec.Default(),
+		handlers: make(map[string][]shared.EventHandler),
+	}
+}
+
+// Register 为事件类型注册处理器，同一事件类型下处理器名称必须唯一。
+func (d *InboxDispatcher) Register(eventType string, handler shared.EventHandler) error {
+	if eventType == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if handler == nil || handler.Name() == "" {
+		return fmt.Errorf("handler with a non-empty name is required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, existing := range d.handlers[eventType] {
+		if existing.Name() == handler.Name() {
+			return fmt.Errorf("handler %s is already registered for event %s", handler.Name(), eventType)
+		}
+	}
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+	return nil
+}
+
+// Publish 使调度器可以直接作为 OutboxWorker 的发布器使用。
+func (d *InboxDispatcher) Publish(ctx context.Context, message OutboxMessage) error {
+	return d.Dispatch(ctx, message)
+}
+
+// Dispatch 将事件交给所有已注册的处理器，任一处理器失败都会返回错误以触发重投；
+// 已成功的处理器在重投时会被跳过。
+func (d *InboxDispatcher) Dispatch(ctx context.Context, message OutboxMessage) error {
+	d.mu.RLock()
+	handlers := append([]shared.EventHandler(nil), d.handlers[message.EventType]...)
+	d.mu.RUnlock()
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	event, err := message.Decode(d.codecs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := d.handle(ctx, message, event, handler); err != nil {
+			errs = append(errs, fmt.Errorf("handler %s: %w", handler.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *InboxDispatcher) handle(ctx context.Context, message OutboxMessage, event shared.DomainEvent, handler shared.EventHandler) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先插入处理记录：并发投递时后到者会阻塞在主键上，待前者提交后因冲突而跳过。
+		err := tx.Create(&po.ProcessedEventPO{
+			EventID:   message.ID,
+			Consumer:  handler.Name(),
+			EventType: message.EventType,
+		}).Error
+		if isDuplicateKeyError(err) {
+			return errEventAlreadyProcessed
+		}
+		if err != nil {
+			return err
+		}
+
+		if contextHandler, ok := handler.(shared.ContextEventHandler); ok {
+			return contextHandler.HandleContext(persistence.ContextWithTx(ctx, tx), event)
+		}
+		return handler.Handle(event)
+	})
+	if errors.Is(err, errEventAlreadyProcessed) {
+		logger.Debug("Inbox event already processed, skipping",
+			zap.String("event_id", message.ID),
+			zap.String("consumer", handler.Name()),
+		)
+		return nil
+	}
+	return err
+}
+
+// IsProcessed 判断事件是否已被指定消费者处理。
+func (d *InboxDispatcher) IsProcessed(ctx context.Context, eventID, consumer string) (bool, error) {
+	var count int64
+	err := d.db.WithContext(ctx).Model(&po.ProcessedEventPO{}).
+		Where("event_id = ? AND consumer = ?", eventID, consumer).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed event: %w", err)
+	}
+	return count > 0, nil
+}
+
+var _ OutboxPublisher = (*InboxDispatcher)(nil)
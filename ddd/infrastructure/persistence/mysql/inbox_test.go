@@ -0,0 +1,162 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/infrastructure/eventcodec"
+	"ddd/infrastructure/persistence"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newMockDB 返回连接到 sqlmock 的 gorm 实例，单条写入不包默认事务，测试结束时校验所有预期都已满足。
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet SQL expectations: %v", err)
+		}
+		sqlDB.Close()
+	})
+	return db, mock
+}
+
+type recordingHandler struct {
+	name  string
+	err   error
+	calls int
+	ctx   context.Context
+}
+
+func (h *recordingHandler) Name() string { return h.name }
+
+func (h *recordingHandler) Handle(shared.DomainEvent) error {
+	h.calls++
+	return h.err
+}
+
+type recordingContextHandler struct {
+	recordingHandler
+}
+
+func (h *recordingContextHandler) HandleContext(ctx context.Context, event shared.DomainEvent) error {
+	h.ctx = ctx
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		if err := tx.Exec("UPDATE order_counters SET confirmed = confirmed + 1").Error; err != nil {
+			return err
+		}
+	}
+	return h.Handle(event)
+}
+
+func newInboxMessage(t *testing.T) OutboxMessage {
+	t.Helper()
+	event := order.RebuildOrderConfirmedEvent("order-1", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC))
+	encoded, err := eventcodec.Default().Encode(event)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	return OutboxMessage{
+		ID:           "event-1",
+		AggregateID:  "order-1",
+		EventType:    encoded.EventType,
+		EventVersion: encoded.Version,
+		Payload:      string(encoded.Payload),
+	}
+}
+
+func TestInboxDispatcherSkipsProcessedEvent(t *testing.T) {
+	db, mock := newMockDB(t)
+	dispatcher := NewInboxDispatcher(db)
+	handler := &recordingHandler{name: "notifier"}
+	message := newInboxMessage(t)
+	if err := dispatcher.Register(message.EventType, handler); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `processed_events`").
+		WithArgs("event-1", "notifier", message.EventType, sqlmock.AnyArg()).
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'event-1-notifier' for key 'PRIMARY'"})
+	mock.ExpectRollback()
+
+	if err := dispatcher.Dispatch(context.Background(), message); err != nil {
+		t.Fatalf("Dispatch() error = %v, want redelivery to be skipped", err)
+	}
+	if handler.calls != 0 {
+		t.Errorf("handler called %d times, want 0 for a processed event", handler.calls)
+	}
+}
+
+func TestInboxDispatcherRollsBackOnHandlerError(t *testing.T) {
+	db, mock := newMockDB(t)
+	dispatcher := NewInboxDispatcher(db)
+	handlerErr := errors.New("downstream unavailable")
+	handler := &recordingHandler{name: "notifier", err: handlerErr}
+	message := newInboxMessage(t)
+	if err := dispatcher.Register(message.EventType, handler); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `processed_events`").
+		WithArgs("event-1", "notifier", message.EventType, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	err := dispatcher.Dispatch(context.Background(), message)
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("Dispatch() error = %v, want the handler error", err)
+	}
+	if handler.calls != 1 {
+		t.Errorf("handler called %d times, want 1", handler.calls)
+	}
+}
+
+func TestInboxDispatcherPassesTxToContextHandler(t *testing.T) {
+	db, mock := newMockDB(t)
+	dispatcher := NewInboxDispatcher(db)
+	handler := &recordingContextHandler{recordingHandler{name: "counter"}}
+	message := newInboxMessage(t)
+	if err := dispatcher.Register(message.EventType, handler); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	// 处理器的写入与处理记录位于同一事务，一并提交。
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `processed_events`").
+		WithArgs("event-1", "counter", message.EventType, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE order_counters").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := dispatcher.Dispatch(context.Background(), message); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if handler.calls != 1 {
+		t.Errorf("handler called %d times, want 1", handler.calls)
+	}
+	if handler.ctx == nil || persistence.TxFromContext(handler.ctx) == nil {
+		t.Error("HandleContext() ctx carries no transaction")
+	}
+}
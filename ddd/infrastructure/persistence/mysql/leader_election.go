@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLeaderRetryInterval = 5 * time.Second
+	DefaultLeaderCheckInterval = 5 * time.Second
+)
+
+type LeaderElectionConfig struct {
+	LockName string
+	// RetryInterval 为未当选时再次尝试获取锁的间隔。
+	RetryInterval time.Duration
+	// CheckInterval 为当选后检查锁是否仍由本连接持有的间隔。
+	CheckInterval time.Duration
+}
+
+// LeaderElector 基于 MySQL GET_LOCK 的主节点选举。
+// 命名锁绑定在单个数据库连接上，持有者进程崩溃或连接断开时 MySQL 自动释放锁，
+// 其他副本在下一次重试时接任，实现故障转移。
+type LeaderElector struct {
+	db       *gorm.DB
+	config   LeaderElectionConfig
+	isLeader atomic.Bool
+}
+
+func NewLeaderElector(db *gorm.DB, config LeaderElectionConfig) (*LeaderElector, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	if config.LockName == "" {
+		return nil, fmt.Errorf("leader lock name is required")
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultLeaderRetryInterval
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultLeaderCheckInterval
+	}
+	return &LeaderElector{db: db, config: config}, nil
+}
+
+// IsLeader 返回当前进程是否持有锁。
+func (e *LeaderElector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// Run 持续参与选举，当选后调用 fn，失去领导权或 ctx 取消时取消 fn 的 ctx 并等待其返回。
+// fn 返回后释放锁并重新参与选举，直到 ctx 取消。
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	for {
+		if err := e.campaign(ctx, fn); err != nil && ctx.Err() == nil {
+			logger.Error("Leader election round failed",
+				zap.String("lock", e.config.LockName),
+				zap.Error(err),
+			)
+		}
+
+		timer := time.NewTimer(e.config.RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (e *LeaderElector) campaign(ctx context.Context, fn func(ctx context.Context) error) error {
+	sqlDB, err := e.db.DB()
+	if err != nil {
+		return err
+	}
+	// GET_LOCK 与连接绑定，必须在同一个连接上获取、检查与释放。
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", e.config.LockName).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		return nil
+	}
+
+	e.isLeader.Store(true)
+	defer e.isLeader.Store(false)
+	logger.Info("Acquired leadership", zap.String("lock", e.config.LockName))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var fnErr error
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		fnErr = fn(leaderCtx)
+	}()
+
+	err = e.holdLeadership(leaderCtx, conn, finished)
+	cancel()
+	<-finished
+	if fnErr != nil && !errors.Is(fnErr, context.Canceled) {
+		err = fnErr
+	}
+
+	// ctx 可能已取消，释放锁使用独立的 ctx；释放失败时关闭连接同样会释放锁。
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), e.config.CheckInterval)
+	defer releaseCancel()
+	if _, releaseErr := conn.ExecContext(releaseCtx, "DO RELEASE_LOCK(?)", e.config.LockName); releaseErr != nil {
+		logger.Warn("Failed to release leader lock", zap.Error(releaseErr))
+	}
+	logger.Info("Released leadership", zap.String("lock", e.config.LockName))
+	return err
+}
+
+// holdLeadership 周期性确认锁仍由当前连接持有，连接断开即视为失去领导权。
+func (e *LeaderElector) holdLeadership(ctx context.Context, conn *sql.Conn, finished <-chan struct{}) error {
+	ticker := time.NewTicker(e.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-finished:
+			return nil
+		case <-ticker.C:
+			var held sql.NullInt64
+			err := conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", e.config.LockName).Scan(&held)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("lost leader lock connection: %w", err)
+			}
+			if held.Int64 != 1 {
+				return fmt.Errorf("leader lock %s is no longer held", e.config.LockName)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+
+	"gorm.io/gorm"
+)
+
+// OrderHistoryRepository 为状态存储的订单保留历史：OrderRepository 每次保存后在同一事务内
+// 追加一行完整状态，按时间点查询时取该时刻之前的最后一行。引入该表之前的变更没有记录。
+type OrderHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderHistoryRepository(db *gorm.DB) *OrderHistoryRepository {
+	return &OrderHistoryRepository{db: db}
+}
+
+func (r *OrderHistoryRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+
+// record 追加订单当前状态，tx 为保存订单的事务。
+func (r *OrderHistoryRepository) record(tx *gorm.DB, o *order.Order) error {
+	snapshot, err := newOrderSnapshot(o)
+	if err != nil {
+		return err
+	}
+	err = tx.Create(&po.OrderHistoryPO{
+		OrderID:       o.ID(),
+		Version:       o.Version(),
+		Status:        string(o.Status()),
+		SchemaVersion: snapshot.SchemaVersion,
+		Payload:       string(snapshot.Payload),
+		RecordedAt:    time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record order history: %w", err)
+	}
+	return nil
+}
+
+func (r *OrderHistoryRepository) FindAsOf(ctx context.Context, orderID string, at time.Time) (*order.Order, error) {
+	var row po.OrderHistoryPO
+	err := r.getDB(ctx).
+		Where("order_id = ? AND recorded_at <= ?", orderID, at).
+		Order("recorded_at DESC, id DESC").
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, order.NewOrderNotFoundError(orderID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order history: %w", err)
+	}
+	if row.SchemaVersion != OrderSnapshotSchemaVersion {
+		return nil, fmt.Errorf("order history of %s uses unsupported schema version %d", orderID, row.SchemaVersion)
+	}
+	return rebuildOrderFromSnapshot(&Snapshot{
+		AggregateID:   row.OrderID,
+		AggregateType: OrderAggregateType,
+		Version:       row.Version,
+		SchemaVersion: row.SchemaVersion,
+		Payload:       []byte(row.Payload),
+	})
+}
+
+// eventsUntil 返回事件流中发生于 at 及之前的前缀。按版本顺序截断而不是逐条过滤，
+// 保证重放的始终是一段连续的事件流。
+func eventsUntil(events []shared.DomainEvent, at time.Time) []shared.DomainEvent {
+	for i, event := range events {
+		if event.OccurredOn().After(at) {
+			return events[:i]
+		}
+	}
+	return events
+}
+
+var _ order.HistoryReader = (*OrderHistoryRepository)(nil)
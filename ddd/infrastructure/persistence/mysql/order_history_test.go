@@ -0,0 +1,167 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+var historyPlacedAt = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+// historyEvents 返回一个在 9:00 下单、9:30 确认、11:00 发货的订单的事件流。
+func historyEvents() []shared.DomainEvent {
+	item := order.RebuildItemFromDTO(order.ItemReconstructionDTO{
+		ID:          "order-1-item-1",
+		ProductID:   "p-1",
+		ProductName: "Keyboard",
+		Quantity:    2,
+		UnitPrice:   *shared.NewMoney(500, "CNY"),
+		Subtotal:    *shared.NewMoney(1000, "CNY"),
+	})
+	return []shared.DomainEvent{
+		order.RebuildOrderPlacedEvent("order-1", "user-1", *shared.NewMoney(1000, "CNY"), historyPlacedAt),
+		order.RebuildOrderItemAddedEvent("order-1", item, historyPlacedAt),
+		order.RebuildOrderConfirmedEvent("order-1", historyPlacedAt.Add(30*time.Minute)),
+		order.RebuildOrderShippedEvent("order-1", historyPlacedAt.Add(2*time.Hour)),
+	}
+}
+
+func TestEventsUntilReplaysOrderAsOf(t *testing.T) {
+	events := historyEvents()
+
+	if got := eventsUntil(events, historyPlacedAt.Add(-time.Minute)); len(got) != 0 {
+		t.Fatalf("eventsUntil() before placement = %d events, want 0", len(got))
+	}
+
+	o, err := order.RebuildFromEvents(eventsUntil(events, historyPlacedAt.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("RebuildFromEvents() error = %v", err)
+	}
+	if o.Status() != order.StatusConfirmed || o.TotalAmount().Amount() != 1000 || len(o.Items()) != 1 {
+		t.Errorf("order as of 10:00 = %s %s %d items, want CONFIRMED 1000 CNY 1 item",
+			o.Status(), o.TotalAmount(), len(o.Items()))
+	}
+	if !o.UpdatedAt().Equal(historyPlacedAt.Add(30 * time.Minute)) {
+		t.Errorf("UpdatedAt() = %s, want the confirmation time", o.UpdatedAt())
+	}
+}
+
+func TestOrderHistoryFindAsOfDecodesLatestRecordedRow(t *testing.T) {
+	db, mock := newMockDB(t)
+	history := NewOrderHistoryRepository(db)
+
+	confirmed, err := order.RebuildFromEvents(historyEvents()[:3])
+	if err != nil {
+		t.Fatalf("RebuildFromEvents() error = %v", err)
+	}
+	snapshot, err := newOrderSnapshot(confirmed)
+	if err != nil {
+		t.Fatalf("newOrderSnapshot() error = %v", err)
+	}
+	at := historyPlacedAt.Add(time.Hour)
+
+	// 取 at 之前最后记录的一行：按记录时间倒序，同一时刻以自增 ID 区分先后。
+	mock.ExpectQuery("SELECT \\* FROM `order_history` WHERE order_id = \\? AND recorded_at <= \\? "+
+		"ORDER BY recorded_at DESC, id DESC.* LIMIT \\?").
+		WithArgs("order-1", at, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "version", "status", "schema_version", "payload", "recorded_at"}).
+			AddRow(7, "order-1", confirmed.Version(), string(confirmed.Status()), snapshot.SchemaVersion,
+				string(snapshot.Payload), historyPlacedAt.Add(30*time.Minute)))
+
+	o, err := history.FindAsOf(context.Background(), "order-1", at)
+	if err != nil {
+		t.Fatalf("FindAsOf() error = %v", err)
+	}
+	if o.Status() != order.StatusConfirmed || o.TotalAmount().Amount() != 1000 || len(o.Items()) != 1 {
+		t.Errorf("order as of 10:00 = %s %s %d items, want CONFIRMED 1000 CNY 1 item",
+			o.Status(), o.TotalAmount(), len(o.Items()))
+	}
+	if o.Version() != confirmed.Version() {
+		t.Errorf("Version() = %d, want %d", o.Version(), confirmed.Version())
+	}
+}
+
+func TestOrderHistoryFindAsOfBeforeFirstRow(t *testing.T) {
+	db, mock := newMockDB(t)
+	history := NewOrderHistoryRepository(db)
+
+	mock.ExpectQuery("SELECT \\* FROM `order_history`").WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := history.FindAsOf(context.Background(), "order-1", historyPlacedAt.Add(-time.Minute))
+	if !errors.Is(err, order.ErrOrderNotFound) {
+		t.Errorf("FindAsOf() error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+// expectOrderLoad 预期 FindByID 读取订单与订单项。
+func expectOrderLoad(mock sqlmock.Sqlmock, status order.Status) {
+	mock.ExpectQuery("SELECT \\* FROM `orders` WHERE id = \\?").
+		WithArgs("order-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "total_amount", "total_currency", "version", "created_at", "updated_at"}).
+			AddRow("order-1", "user-1", string(status), 1000, "CNY", 3, historyPlacedAt, historyPlacedAt))
+	mock.ExpectQuery("SELECT \\* FROM `order_items` WHERE order_id = \\?").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}))
+}
+
+// payloadContains 匹配包含 fragment 的 outbox payload。
+type payloadContains string
+
+func (p payloadContains) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.Contains(s, string(p))
+}
+
+func TestOrderRepositoryRemoveWritesHistoryAndOutbox(t *testing.T) {
+	for _, status := range []order.Status{order.StatusConfirmed, order.StatusDelivered} {
+		t.Run(string(status), func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := NewOrderRepository(db)
+
+			// 订单变更、历史与取消事件在同一个工作单元事务内提交。
+			mock.ExpectBegin()
+			expectOrderLoad(mock, status)
+			mock.ExpectExec("UPDATE `orders` SET .* WHERE id = \\? AND version = \\?").
+				WithArgs(string(order.StatusCancelled), 1000, "CNY", sqlmock.AnyArg(), 4, "order-1", 3).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("INSERT INTO `order_history`").
+				WithArgs("order-1", 4, string(order.StatusCancelled), OrderSnapshotSchemaVersion, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(8, 1))
+			mock.ExpectQuery("SELECT COALESCE\\(MAX\\(aggregate_sequence\\), 0\\) FROM `outbox_events`").
+				WithArgs("order-1").
+				WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(4))
+			mock.ExpectExec("INSERT INTO `outbox_events`").
+				WithArgs(sqlmock.AnyArg(), "order-1", int64(5), "order.cancelled", 1, payloadContains(`"reason":"removed"`),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			if err := repo.Remove(context.Background(), "order-1"); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryRemoveSkipsCancelledOrder(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewOrderRepository(db)
+
+	mock.ExpectBegin()
+	expectOrderLoad(mock, order.StatusCancelled)
+	mock.ExpectCommit()
+
+	if err := repo.Remove(context.Background(), "order-1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"context"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+)
+
+// OrderRemovalReason 是仓储 Remove 产生的取消事件携带的原因。
+const OrderRemovalReason = "removed"
+
+// removeOrder 加载订单、撤销并经 repo.Save 保存，取消事件与订单变更在同一事务内写入 outbox。
+// ctx 未携带事务时在 factory 创建的工作单元内执行；已携带事务时（调用方的工作单元内）
+// 直接写入该事务的 outbox，由调用方提交，此时不做进程内分发。
+func removeOrder(ctx context.Context, factory shared.UnitOfWorkFactory, repo order.Repository, id string) error {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		o, err := saveRemovedOrder(ctx, repo, id)
+		if err != nil || o == nil {
+			return err
+		}
+		return NewOutboxRepository(tx).SaveEvents(ctx, o.PullEvents())
+	}
+
+	uow := factory.New()
+	return uow.Execute(ctx, func(ctx context.Context) error {
+		o, err := saveRemovedOrder(ctx, repo, id)
+		if err != nil || o == nil {
+			return err
+		}
+		uow.RegisterDirty(o)
+		return nil
+	})
+}
+
+// saveRemovedOrder 返回已保存但事件尚未写入 outbox 的订单；订单已取消时返回 nil。
+func saveRemovedOrder(ctx context.Context, repo order.Repository, id string) (*order.Order, error) {
+	o, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !o.Remove(OrderRemovalReason) {
+		return nil, nil
+	}
+	if err := repo.Save(ctx, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -3,21 +3,34 @@ package mysql
 import (
 	"context"
 	"errors"
+	"time"
 
 	"ddd/domain/order"
 	"ddd/domain/shared"
 	"ddd/infrastructure/persistence"
 	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/infrastructure/persistence/retry"
 
 	"gorm.io/gorm"
 )
 
 type OrderRepository struct {
-	db *gorm.DB
+	db         *gorm.DB
+	history    *OrderHistoryRepository
+	uowFactory shared.UnitOfWorkFactory
 }
 
 func NewOrderRepository(db *gorm.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+	return &OrderRepository{
+		db:         db,
+		history:    NewOrderHistoryRepository(db),
+		uowFactory: NewUnitOfWorkFactory(db, retry.DefaultConfig),
+	}
+}
+
+// SetUnitOfWorkFactory 设置 Remove 使用的工作单元，使取消事件按应用配置重试并在提交后进程内分发。
+func (r *OrderRepository) SetUnitOfWorkFactory(factory shared.UnitOfWorkFactory) {
+	r.uowFactory = factory
 }
 
 func (r *OrderRepository) getDB(ctx context.Context) *gorm.DB {
@@ -99,6 +112,10 @@ func (r *OrderRepository) saveWithTx(tx *gorm.DB, o *order.Order) error {
 		}
 	}
 
+	if err := r.history.record(tx, o); err != nil {
+		return err
+	}
+
 	o.ClearDirtyTracking()
 	return nil
 }
@@ -126,6 +143,11 @@ func (r *OrderRepository) FindByID(ctx context.Context, id string) (*order.Order
 	return orderPO.ToDomain(itemPOs), nil
 }
 
+// FindAsOf 从 order_history 还原订单在 at 时刻的状态。
+func (r *OrderRepository) FindAsOf(ctx context.Context, orderID string, at time.Time) (*order.Order, error) {
+	return r.history.FindAsOf(ctx, orderID, at)
+}
+
 func (r *OrderRepository) FindByUserID(ctx context.Context, userID string) ([]*order.Order, error) {
 	spec := order.ByUserIDSpecification{UserID: userID}
 	return r.FindBySpecification(ctx, spec)
@@ -245,18 +267,13 @@ func (r *OrderRepository) applyConcreteSpecification(db *gorm.DB, spec shared.Sp
 	}
 }
 
+// Remove 将订单标记为已取消而不删除行，任何未取消的订单都可移除。经 Save 递增版本并记录历史，
+// 取消事件经工作单元写入 outbox。
 func (r *OrderRepository) Remove(ctx context.Context, id string) error {
-	result := r.getDB(ctx).
-		Model(&po.OrderPO{}).
-		Where("id = ?", id).
-		Update("status", string(order.StatusCancelled))
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return order.ErrOrderNotFound
-	}
-	return nil
+	return removeOrder(ctx, r.uowFactory, r, id)
 }
 
-var _ order.Repository = (*OrderRepository)(nil)
+var (
+	_ order.Repository    = (*OrderRepository)(nil)
+	_ order.HistoryReader = (*OrderRepository)(nil)
+)
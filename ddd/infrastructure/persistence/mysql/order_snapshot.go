@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+)
+
+// OrderSnapshotSchemaVersion 是订单快照 payload 的结构版本。orderSnapshot 或
+// order.ReconstructionDTO 的字段含义变化时递增，旧版本快照随即失效，订单退回完整重放。
+const OrderSnapshotSchemaVersion = 1
+
+type orderSnapshot struct {
+	ID            string              `json:"id"`
+	UserID        string              `json:"user_id"`
+	Items         []orderSnapshotItem `json:"items"`
+	TotalAmount   int64               `json:"total_amount"`
+	TotalCurrency string              `json:"total_currency"`
+	Status        string              `json:"status"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+type orderSnapshotItem struct {
+	ID               string `json:"id"`
+	ProductID        string `json:"product_id"`
+	ProductName      string `json:"product_name"`
+	Quantity         int    `json:"quantity"`
+	UnitPrice        int64  `json:"unit_price"`
+	UnitCurrency     string `json:"unit_currency"`
+	Subtotal         int64  `json:"subtotal"`
+	SubtotalCurrency string `json:"subtotal_currency"`
+}
+
+// newOrderSnapshot 将订单当前状态编码为快照，版本取自 ReconstructionDTO.Version。
+func newOrderSnapshot(o *order.Order) (Snapshot, error) {
+	dto := o.Snapshot()
+	payload := orderSnapshot{
+		ID:            dto.ID,
+		UserID:        dto.UserID,
+		Items:         make([]orderSnapshotItem, len(dto.Items)),
+		TotalAmount:   dto.TotalAmount.Amount(),
+		TotalCurrency: dto.TotalAmount.Currency(),
+		Status:        string(dto.Status),
+		CreatedAt:     dto.CreatedAt,
+		UpdatedAt:     dto.UpdatedAt,
+	}
+	for i, item := range dto.Items {
+		payload.Items[i] = orderSnapshotItem{
+			ID:               item.ID(),
+			ProductID:        item.ProductID(),
+			ProductName:      item.ProductName(),
+			Quantity:         item.Quantity(),
+			UnitPrice:        item.UnitPrice().Amount(),
+			UnitCurrency:     item.UnitPrice().Currency(),
+			Subtotal:         item.Subtotal().Amount(),
+			SubtotalCurrency: item.Subtotal().Currency(),
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to encode order snapshot: %w", err)
+	}
+	return Snapshot{
+		AggregateID:   dto.ID,
+		AggregateType: OrderAggregateType,
+		Version:       dto.Version,
+		SchemaVersion: OrderSnapshotSchemaVersion,
+		Payload:       data,
+	}, nil
+}
+
+// rebuildOrderFromSnapshot 还原快照时刻的订单，调用方随后重放快照版本之后的事件。
+func rebuildOrderFromSnapshot(snapshot *Snapshot) (*order.Order, error) {
+	var payload orderSnapshot
+	if err := json.Unmarshal(snapshot.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode order snapshot: %w", err)
+	}
+
+	items := make([]order.OrderItem, len(payload.Items))
+	for i, item := range payload.Items {
+		items[i] = order.RebuildItemFromDTO(order.ItemReconstructionDTO{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   *shared.NewMoney(item.UnitPrice, item.UnitCurrency),
+			Subtotal:    *shared.NewMoney(item.Subtotal, item.SubtotalCurrency),
+		})
+	}
+	return order.RebuildFromDTO(order.ReconstructionDTO{
+		ID:          payload.ID,
+		UserID:      payload.UserID,
+		Items:       items,
+		TotalAmount: *shared.NewMoney(payload.TotalAmount, payload.TotalCurrency),
+		Status:      order.Status(payload.Status),
+		Version:     snapshot.Version,
+		CreatedAt:   payload.CreatedAt,
+		UpdatedAt:   payload.UpdatedAt,
+	}), nil
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+)
+
+func TestOrderSnapshotThenReplayMatchesFullReplay(t *testing.T) {
+	o, err := order.NewOrder("user-1", []order.ItemRequest{
+		{ProductID: "p-1", ProductName: "Keyboard", Quantity: 2, UnitPrice: *shared.NewMoney(500, "CNY")},
+	})
+	if err != nil {
+		t.Fatalf("NewOrder() error = %v", err)
+	}
+	head := o.UnstoredEvents()
+	o.MarkEventsStored(len(head))
+
+	snapshot, err := newOrderSnapshot(o)
+	if err != nil {
+		t.Fatalf("newOrderSnapshot() error = %v", err)
+	}
+	if snapshot.Version != len(head) || snapshot.SchemaVersion != OrderSnapshotSchemaVersion {
+		t.Fatalf("snapshot version = %d/%d, want %d/%d", snapshot.Version, snapshot.SchemaVersion, len(head), OrderSnapshotSchemaVersion)
+	}
+
+	if err := o.AddItem("p-2", "Mouse", 1, *shared.NewMoney(200, "CNY")); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if err := o.Confirm(); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	tail := o.UnstoredEvents()
+
+	fromSnapshot, err := rebuildOrderFromSnapshot(&snapshot)
+	if err != nil {
+		t.Fatalf("rebuildOrderFromSnapshot() error = %v", err)
+	}
+	if err := fromSnapshot.Replay(tail); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	full, err := order.RebuildFromEvents(append(head, tail...))
+	if err != nil {
+		t.Fatalf("RebuildFromEvents() error = %v", err)
+	}
+
+	if fromSnapshot.Version() != full.Version() || fromSnapshot.Status() != full.Status() ||
+		!fromSnapshot.TotalAmount().Equals(full.TotalAmount()) || !reflect.DeepEqual(fromSnapshot.Items(), full.Items()) {
+		t.Errorf("snapshot rehydration = v%d %s %s %v, full replay = v%d %s %s %v",
+			fromSnapshot.Version(), fromSnapshot.Status(), fromSnapshot.TotalAmount(), fromSnapshot.Items(),
+			full.Version(), full.Status(), full.TotalAmount(), full.Items())
+	}
+}
+
+func TestSnapshotStoreDue(t *testing.T) {
+	store := NewSnapshotStore(nil, map[string]int{OrderAggregateType: 10})
+	cases := []struct {
+		aggregateType string
+		from, to      int
+		want          bool
+	}{
+		{OrderAggregateType, 0, 9, false},
+		{OrderAggregateType, 9, 10, true},
+		{OrderAggregateType, 8, 13, true},
+		{OrderAggregateType, 10, 12, false},
+		{"user", 0, 100, false},
+	}
+	for _, c := range cases {
+		if got := store.Due(c.aggregateType, c.from, c.to); got != c.want {
+			t.Errorf("Due(%s, %d, %d) = %v, want %v", c.aggregateType, c.from, c.to, got, c.want)
+		}
+	}
+}
@@ -2,21 +2,50 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+	"unicode/utf8"
 
 	"ddd/domain/shared"
+	"ddd/infrastructure/eventcodec"
 	"ddd/infrastructure/persistence"
 	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/infrastructure/persistence/retry"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// OutboxOrderingMode 控制认领事件时的顺序保证。
+type OutboxOrderingMode string
+
+const (
+	// OutboxOrderingNone 仅按 created_at 认领，失败事件重试时可能晚于同聚合的后续事件。
+	OutboxOrderingNone OutboxOrderingMode = "none"
+	// OutboxOrderingPerAggregate 同一聚合内严格按 aggregate_sequence 投递：
+	// 前序事件未发布（或未被人工丢弃）前不会认领后续事件，其他聚合不受影响。
+	OutboxOrderingPerAggregate OutboxOrderingMode = "per_aggregate"
+)
+
+// ErrOutboxLeaseLost 表示事件已不再由调用方持有：租约过期后被回收或被其他 worker 重新认领，
+// 本次投递结果不再写回，以免覆盖新持有者的状态。
+var ErrOutboxLeaseLost = errors.New("outbox event lease lost")
+
+// maxLastErrorLength 限制 last_error 的写入长度，避免超长下游响应撑大行。
+const maxLastErrorLength = 2000
+
+// dueCondition 过滤尚未到达重试时间的事件，next_attempt_at 为空表示立即可投递。
+const dueCondition = "next_attempt_at IS NULL OR next_attempt_at <= NOW(3)"
+
 type OutboxRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	codecs *eventcodec.Registry
 }
 
+// NewOutboxRepository 使用 eventcodec.Default 序列化事件，未注册的事件类型无法写入。
 func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
-	return &OutboxRepository{db: db}
+	return &OutboxRepository{db: db, codecs: eventcodec.Default()}
 }
 func (r *OutboxRepository) getDB(ctx context.Context) *gorm.DB {
 	if tx := persistence.TxFromContext(ctx); tx != nil {
@@ -25,21 +54,66 @@ func (r *OutboxRepository) getDB(ctx context.Context) *gorm.DB {
 	return r.db.WithContext(ctx)
 }
 func (r *OutboxRepository) SaveEvent(ctx context.Context, event shared.DomainEvent) error {
-	if err := shared.ValidateEvent(event); err != nil {
-		return fmt.Errorf("invalid domain event: %w", err)
+	return r.SaveEvents(ctx, []shared.DomainEvent{event})
+}
+
+// SaveEvents 批量写入事件，并为每个聚合分配连续递增的 aggregate_sequence。
+// 序号在聚合行锁（乐观锁更新）之后读取，同一聚合的并发事务会被串行化。
+func (r *OutboxRepository) SaveEvents(ctx context.Context, events []shared.DomainEvent) error {
+	for _, event := range events {
+		if err := shared.ValidateEvent(event); err != nil {
+			return fmt.Errorf("invalid domain event: %w", err)
+		}
+	}
+	if len(events) == 0 {
+		return nil
 	}
 	if tx := persistence.TxFromContext(ctx); tx != nil {
-		return r.saveEventWithTx(tx, event)
+		return r.saveEventsWithTx(tx, events)
 	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return r.saveEventWithTx(tx, event)
+		return r.saveEventsWithTx(tx, events)
 	})
 }
-func (r *OutboxRepository) saveEventWithTx(tx *gorm.DB, event shared.DomainEvent) error {
-	outboxPO, err := po.FromDomainEvent(event)
+func (r *OutboxRepository) saveEventsWithTx(tx *gorm.DB, events []shared.DomainEvent) error {
+	sequences := make(map[string]int64)
+	for _, event := range events {
+		aggregateID := event.GetAggregateID()
+		sequence, ok := sequences[aggregateID]
+		if !ok {
+			var err error
+			sequence, err = r.lastAggregateSequence(tx, aggregateID)
+			if err != nil {
+				return err
+			}
+		}
+		sequence++
+		sequences[aggregateID] = sequence
+
+		if err := r.saveEventWithTx(tx, event, sequence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (r *OutboxRepository) lastAggregateSequence(tx *gorm.DB, aggregateID string) (int64, error) {
+	var sequence int64
+	err := tx.Model(&po.OutboxEventPO{}).
+		Select("COALESCE(MAX(aggregate_sequence), 0)").
+		Where("aggregate_id = ?", aggregateID).
+		Scan(&sequence).Error
 	if err != nil {
-		return fmt.Errorf("failed to convert domain event: %w", err)
+		return 0, fmt.Errorf("failed to load aggregate sequence: %w", err)
 	}
+	return sequence, nil
+}
+func (r *OutboxRepository) saveEventWithTx(tx *gorm.DB, event shared.DomainEvent, sequence int64) error {
+	encoded, err := r.codecs.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to serialize domain event: %w", err)
+	}
+	outboxPO := po.FromDomainEvent(event, encoded.Payload, encoded.Version)
+	outboxPO.AggregateSequence = sequence
 	if err := tx.Create(outboxPO).Error; err != nil {
 		return fmt.Errorf("failed to save event to outbox: %w", err)
 	}
@@ -51,6 +125,7 @@ func (r *OutboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]*
 	db := r.getDB(ctx)
 
 	err := db.Where("status = ?", string(po.EventStatusPending)).
+		Where(dueCondition).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&events).Error
@@ -61,6 +136,112 @@ func (r *OutboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]*
 
 	return events, nil
 }
+
+// ClaimPendingEvents 使用 FOR UPDATE SKIP LOCKED 原子认领一批待发布事件，并写入租约。
+// 多个 worker 并发调用时各自拿到互不重叠的事件集合。
+// 按聚合有序模式下每个聚合每次最多认领一条（即其队首事件）。
+func (r *OutboxRepository) ClaimPendingEvents(ctx context.Context, owner string, limit int, leaseDuration time.Duration, ordering OutboxOrderingMode) ([]*po.OutboxEventPO, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("lease owner is required")
+	}
+	if leaseDuration <= 0 {
+		return nil, fmt.Errorf("lease duration must be positive")
+	}
+
+	var events []*po.OutboxEventPO
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{
+			Strength: clause.LockingStrengthUpdate,
+			Options:  clause.LockingOptionsSkipLocked,
+		}).
+			Where("status = ?", string(po.EventStatusPending)).
+			Where(dueCondition)
+		if ordering == OutboxOrderingPerAggregate {
+			query = query.Where("NOT EXISTS (?)", tx.Session(&gorm.Session{NewDB: true}).
+				Table("outbox_events AS prev").
+				Select("1").
+				Where("prev.aggregate_id = outbox_events.aggregate_id").
+				Where("prev.aggregate_sequence < outbox_events.aggregate_sequence").
+				Where("prev.status NOT IN ?", []string{string(po.EventStatusPublished), string(po.EventStatusDiscarded)}))
+		}
+		err := query.
+			Order("created_at ASC").
+			Order("aggregate_sequence ASC").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		eventIDs := make([]string, len(events))
+		for i, event := range events {
+			eventIDs[i] = event.ID
+		}
+
+		return tx.Model(&po.OutboxEventPO{}).
+			Where("id IN ?", eventIDs).
+			Updates(map[string]interface{}{
+				"status":           string(po.EventStatusProcessing),
+				"lease_owner":      owner,
+				"lease_expires_at": gorm.Expr("DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)", leaseDuration.Microseconds()),
+				"updated_at":       gorm.Expr("NOW()"),
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending events: %w", err)
+	}
+
+	leaseExpiresAt := time.Now().Add(leaseDuration)
+	for _, event := range events {
+		event.Status = string(po.EventStatusProcessing)
+		event.LeaseOwner = owner
+		event.LeaseExpiresAt = &leaseExpiresAt
+	}
+
+	return events, nil
+}
+
+// RecoverExpiredEvents 将租约已过期的 PROCESSING 事件退回 PENDING 并累加重试次数，
+// 达到 maxRetries 的事件转为 FAILED。没有租约字段的遗留行在 updated_at 之后超过 leaseDuration
+// 才视为过期，leaseDuration 应与认领时使用的租约时长一致。返回被回收事件回收前的快照。
+func (r *OutboxRepository) RecoverExpiredEvents(ctx context.Context, maxRetries int, limit int, leaseDuration time.Duration, backoff retry.Config) ([]*po.OutboxEventPO, error) {
+	var events []*po.OutboxEventPO
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// 兼容租约字段上线前遗留的 PROCESSING 行：以 updated_at 判断是否卡死。
+		err := tx.Clauses(clause.Locking{
+			Strength: clause.LockingStrengthUpdate,
+			Options:  clause.LockingOptionsSkipLocked,
+		}).
+			Where("status = ?", string(po.EventStatusProcessing)).
+			Where("(lease_expires_at IS NOT NULL AND lease_expires_at < NOW(3)) OR (lease_expires_at IS NULL AND updated_at < DATE_SUB(NOW(), INTERVAL ? SECOND))",
+				int64(leaseDuration/time.Second)).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			lastError := fmt.Sprintf("lease held by %s expired before publish completed", event.LeaseOwner)
+			err := tx.Model(&po.OutboxEventPO{}).
+				Where("id = ? AND status = ?", event.ID, string(po.EventStatusProcessing)).
+				Updates(failureUpdates(event.RetryCount, maxRetries, lastError, backoff)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover expired events: %w", err)
+	}
+
+	return events, nil
+}
 func (r *OutboxRepository) MarkEventProcessing(ctx context.Context, eventID string) error {
 	db := r.getDB(ctx)
 	result := db.Model(&po.OutboxEventPO{}).
@@ -79,50 +260,155 @@ func (r *OutboxRepository) MarkEventProcessing(ctx context.Context, eventID stri
 
 	return nil
 }
-func (r *OutboxRepository) MarkEventPublished(ctx context.Context, eventID string) error {
+
+// MarkEventPublished 将 owner 仍持有租约的事件标记为已发布。
+func (r *OutboxRepository) MarkEventPublished(ctx context.Context, owner, eventID string) error {
 	db := r.getDB(ctx)
 	result := db.Model(&po.OutboxEventPO{}).
-		Where("id = ?", eventID).
+		Where("id = ? AND status = ? AND lease_owner = ?", eventID, string(po.EventStatusProcessing), owner).
 		Updates(map[string]interface{}{
-			"status":     string(po.EventStatusPublished),
-			"updated_at": gorm.Expr("NOW()"),
+			"status":           string(po.EventStatusPublished),
+			"next_attempt_at":  nil,
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"updated_at":       gorm.Expr("NOW()"),
 		})
 
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("event not found: %s", eventID)
+		return fmt.Errorf("%w: %s", ErrOutboxLeaseLost, eventID)
 	}
 
 	return nil
 }
-func (r *OutboxRepository) MarkEventFailed(ctx context.Context, eventID string, maxRetries int) error {
+
+// ReleaseEvents 将仍由 owner 持有、尚未投递的事件退回 PENDING，不计入重试次数，
+// 用于 worker 停止时交还已认领但未处理的事件，其他副本无需等待租约过期即可接手。
+func (r *OutboxRepository) ReleaseEvents(ctx context.Context, owner string, eventIDs []string) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+	result := r.getDB(ctx).Model(&po.OutboxEventPO{}).
+		Where("id IN ? AND status = ? AND lease_owner = ?", eventIDs, string(po.EventStatusProcessing), owner).
+		Updates(map[string]interface{}{
+			"status":           string(po.EventStatusPending),
+			"lease_owner":      "",
+			"lease_expires_at": nil,
+			"updated_at":       gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to release outbox events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MarkEventFailed 记录失败原因并累加重试次数：未达上限时按指数退避写入 next_attempt_at 后退回 PENDING，
+// 否则转为 FAILED。只更新 owner 仍持有租约的事件。
+func (r *OutboxRepository) MarkEventFailed(ctx context.Context, owner, eventID string, publishErr error, maxRetries int, backoff retry.Config) error {
 	db := r.getDB(ctx)
 	var event po.OutboxEventPO
 	if err := db.First(&event, "id = ?", eventID).Error; err != nil {
 		return fmt.Errorf("failed to find event: %w", err)
 	}
 
-	newRetryCount := event.RetryCount + 1
-	newStatus := string(po.EventStatusFailed)
-	if newRetryCount < maxRetries {
-		newStatus = string(po.EventStatusPending)
+	lastError := ""
+	if publishErr != nil {
+		lastError = publishErr.Error()
 	}
 
 	result := db.Model(&po.OutboxEventPO{}).
-		Where("id = ?", eventID).
-		Updates(map[string]interface{}{
-			"status":      newStatus,
-			"retry_count": newRetryCount,
-			"updated_at":  gorm.Expr("NOW()"),
-		})
+		Where("id = ? AND status = ? AND lease_owner = ?", eventID, string(po.EventStatusProcessing), owner).
+		Updates(failureUpdates(event.RetryCount, maxRetries, lastError, backoff))
 
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrOutboxLeaseLost, eventID)
+	}
 
 	return nil
 }
 
+// truncateUTF8 将 s 截断到不超过 max 字节，并退回到完整字符的边界，避免写入残缺的多字节字符。
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+func failureUpdates(retryCount, maxRetries int, lastError string, backoff retry.Config) map[string]interface{} {
+	newRetryCount := retryCount + 1
+	lastError = truncateUTF8(lastError, maxLastErrorLength)
+
+	updates := map[string]interface{}{
+		"status":           string(po.EventStatusFailed),
+		"retry_count":      newRetryCount,
+		"last_error":       lastError,
+		"next_attempt_at":  nil,
+		"lease_owner":      "",
+		"lease_expires_at": nil,
+		"updated_at":       gorm.Expr("NOW()"),
+	}
+	if newRetryCount < maxRetries {
+		delay := retry.ExponentialBackoffWithJitter(newRetryCount, backoff)
+		updates["status"] = string(po.EventStatusPending)
+		updates["next_attempt_at"] = gorm.Expr("DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)", delay.Microseconds())
+	}
+	return updates
+}
+
 var _ shared.OutboxRepository = (*OutboxRepository)(nil)
+
+// OutboxStats 是 outbox 表的积压快照。
+type OutboxStats struct {
+	Pending     int64
+	Processing  int64
+	DeadLetters int64
+	// OldestPendingAt 为最早一条尚未发布（PENDING 或 PROCESSING）事件的创建时间，无积压时为 nil。
+	OldestPendingAt *time.Time
+}
+
+// Stats 按状态统计积压与死信数量，走 (status, created_at) 索引。
+func (r *OutboxRepository) Stats(ctx context.Context) (*OutboxStats, error) {
+	var rows []struct {
+		Status   string
+		Count    int64
+		OldestAt *time.Time
+	}
+	err := r.getDB(ctx).Model(&po.OutboxEventPO{}).
+		Select("status, COUNT(*) AS count, MIN(created_at) AS oldest_at").
+		Where("status IN ?", []string{
+			string(po.EventStatusPending),
+			string(po.EventStatusProcessing),
+			string(po.EventStatusFailed),
+		}).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox stats: %w", err)
+	}
+
+	stats := &OutboxStats{}
+	for _, row := range rows {
+		switch po.EventStatus(row.Status) {
+		case po.EventStatusPending:
+			stats.Pending = row.Count
+		case po.EventStatusProcessing:
+			stats.Processing = row.Count
+		case po.EventStatusFailed:
+			stats.DeadLetters = row.Count
+			continue
+		}
+		if row.OldestAt != nil && (stats.OldestPendingAt == nil || row.OldestAt.Before(*stats.OldestPendingAt)) {
+			stats.OldestPendingAt = row.OldestAt
+		}
+	}
+	return stats, nil
+}
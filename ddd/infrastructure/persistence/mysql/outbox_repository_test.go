@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ddd/infrastructure/persistence/mysql/po"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTruncateUTF8KeepsRuneBoundary(t *testing.T) {
+	cases := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 3, "abc"},
+		{"错误信息", 4, "错"},
+		{"错误信息", 6, "错误"},
+		{"a错", 2, "a"},
+	}
+	for _, c := range cases {
+		got := truncateUTF8(c.in, c.max)
+		if got != c.want || !utf8.ValidString(got) {
+			t.Errorf("truncateUTF8(%q, %d) = %q, want %q", c.in, c.max, got, c.want)
+		}
+	}
+}
+
+func TestRecoverExpiredEventsUsesLeaseDurationForLegacyRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `outbox_events` WHERE status = \\? AND .*INTERVAL \\? SECOND.* FOR UPDATE SKIP LOCKED").
+		WithArgs(string(po.EventStatusProcessing), int64(120), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	_, err := repo.RecoverExpiredEvents(context.Background(), 5, 10, 2*time.Minute, DefaultOutboxRetryBackoff)
+	if err != nil {
+		t.Fatalf("RecoverExpiredEvents() error = %v", err)
+	}
+}
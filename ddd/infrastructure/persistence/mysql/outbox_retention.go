@@ -0,0 +1,229 @@
+package mysql
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultRetentionBatchSize  = 500
+	DefaultRetentionBatchPause = 200 * time.Millisecond
+	DefaultRetentionInterval   = time.Hour
+)
+
+// OutboxArchiver 在已发布事件被删除前将其转存。
+// Archive 与删除在同一事务内调用，返回错误会使本批次回滚。
+type OutboxArchiver interface {
+	Archive(ctx context.Context, tx *gorm.DB, events []*po.OutboxEventPO) error
+}
+
+// TableOutboxArchiver 将事件写入 outbox_events_archive，与删除同事务提交。
+type TableOutboxArchiver struct{}
+
+func (a *TableOutboxArchiver) Archive(ctx context.Context, tx *gorm.DB, events []*po.OutboxEventPO) error {
+	archivePOs := make([]po.OutboxEventArchivePO, len(events))
+	for i, event := range events {
+		archivePOs[i] = event.ToArchive()
+	}
+	// 重复执行（例如删除失败后重跑）时忽略已归档的行。
+	return tx.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&archivePOs).Error
+}
+
+// FileOutboxArchiver 将事件按天追加到 gzip 压缩的 JSONL 文件。
+// 文件写入不受数据库事务保护，删除失败重跑时同一事件可能被重复归档。
+type FileOutboxArchiver struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileOutboxArchiver(dir string) (*FileOutboxArchiver, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("archive directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &FileOutboxArchiver{dir: dir}, nil
+}
+
+func (a *FileOutboxArchiver) Archive(ctx context.Context, tx *gorm.DB, events []*po.OutboxEventPO) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	path := filepath.Join(a.dir, fmt.Sprintf("outbox_events-%s.jsonl.gz", time.Now().Format("20060102")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+	defer file.Close()
+
+	// 每批写入一个独立的 gzip member，多个 member 拼接后仍是合法的 gzip 流。
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+	for _, event := range events {
+		if err := encoder.Encode(event.ToArchive()); err != nil {
+			gz.Close()
+			return fmt.Errorf("failed to write archive record: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to flush archive file: %w", err)
+	}
+	return file.Sync()
+}
+
+// OutboxRetentionConfig 描述已发布事件的保留策略。
+type OutboxRetentionConfig struct {
+	Retention  time.Duration
+	Interval   time.Duration
+	BatchSize  int
+	BatchPause time.Duration
+}
+
+func (c *OutboxRetentionConfig) applyDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = DefaultRetentionInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultRetentionBatchSize
+	}
+	if c.BatchPause < 0 {
+		c.BatchPause = DefaultRetentionBatchPause
+	}
+}
+
+// OutboxRetentionJob 周期性地归档并小批量删除超出保留期的 PUBLISHED 事件，
+// 每批独立事务并在批次间停顿，避免长事务与大范围锁。
+type OutboxRetentionJob struct {
+	db       *gorm.DB
+	archiver OutboxArchiver
+	config   OutboxRetentionConfig
+}
+
+// NewOutboxRetentionJob 创建保留任务，archiver 为 nil 时直接删除不归档。
+func NewOutboxRetentionJob(db *gorm.DB, archiver OutboxArchiver, config OutboxRetentionConfig) (*OutboxRetentionJob, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	if config.Retention <= 0 {
+		return nil, fmt.Errorf("retention must be positive")
+	}
+	config.applyDefaults()
+
+	return &OutboxRetentionJob{
+		db:       db,
+		archiver: archiver,
+		config:   config,
+	}, nil
+}
+
+func (j *OutboxRetentionJob) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Outbox retention purge failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce 处理所有超出保留期的事件，返回本次删除的行数。
+func (j *OutboxRetentionJob) PurgeOnce(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-j.config.Retention)
+	var total int64
+
+	for {
+		deleted, err := j.purgeBatch(ctx, cutoff)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted < int64(j.config.BatchSize) {
+			break
+		}
+
+		timer := time.NewTimer(j.config.BatchPause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return total, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if total > 0 {
+		logger.Info("Outbox retention purge completed",
+			zap.Int64("deleted", total),
+			zap.Time("cutoff", cutoff),
+		)
+	}
+	return total, nil
+}
+
+func (j *OutboxRetentionJob) purgeBatch(ctx context.Context, cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := j.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []*po.OutboxEventPO
+		err := tx.Clauses(clause.Locking{
+			Strength: clause.LockingStrengthUpdate,
+			Options:  clause.LockingOptionsSkipLocked,
+		}).
+			Where("status = ? AND created_at < ?", string(po.EventStatusPublished), cutoff).
+			// 保留每个聚合序号最大的一行，保证 SaveEvents 分配的 aggregate_sequence 持续递增。
+			Where("EXISTS (?)", tx.Session(&gorm.Session{NewDB: true}).
+				Table("outbox_events AS newer").
+				Select("1").
+				Where("newer.aggregate_id = outbox_events.aggregate_id").
+				Where("newer.aggregate_sequence > outbox_events.aggregate_sequence")).
+			Order("created_at ASC").
+			Limit(j.config.BatchSize).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if j.archiver != nil {
+			if err := j.archiver.Archive(ctx, tx, events); err != nil {
+				return err
+			}
+		}
+
+		eventIDs := make([]string, len(events))
+		for i, event := range events {
+			eventIDs[i] = event.ID
+		}
+		result := tx.Where("id IN ?", eventIDs).Delete(&po.OutboxEventPO{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge published events: %w", err)
+	}
+	return deleted, nil
+}
@@ -2,41 +2,175 @@ package mysql
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"ddd/domain/shared"
+	"ddd/infrastructure/eventcodec"
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/infrastructure/persistence/retry"
 	"ddd/pkg/logger"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// OutboxMessage 是交给发布器的 outbox 事件，ID 在重试间保持不变，可供消费方去重。
+type OutboxMessage struct {
+	ID                string
+	AggregateID       string
+	AggregateSequence int64
+	EventType         string
+	EventVersion      int
+	Payload           string
+	CreatedAt         time.Time
+}
+
+// Decode 按事件类型与 schema 版本将 payload 还原为具体的领域事件。
+func (m OutboxMessage) Decode(codecs *eventcodec.Registry) (shared.DomainEvent, error) {
+	return codecs.Decode(m.EventType, m.EventVersion, []byte(m.Payload))
+}
+
+func newOutboxMessage(event *po.OutboxEventPO) OutboxMessage {
+	return OutboxMessage{
+		ID:                event.ID,
+		AggregateID:       event.AggregateID,
+		AggregateSequence: event.AggregateSequence,
+		EventType:         event.EventType,
+		EventVersion:      event.EventVersion,
+		Payload:           event.Payload,
+		CreatedAt:         event.CreatedAt,
+	}
+}
+
 type OutboxPublisher interface {
-	Publish(ctx context.Context, eventType, payload string) error
+	Publish(ctx context.Context, message OutboxMessage) error
 }
 type LoggingOutboxPublisher struct{}
 
-func (p *LoggingOutboxPublisher) Publish(ctx context.Context, eventType, payload string) error {
+func (p *LoggingOutboxPublisher) Publish(ctx context.Context, message OutboxMessage) error {
 	logger.Info("Outbox event published",
-		zap.String("event_type", eventType),
-		zap.String("payload", payload),
+		zap.String("event_id", message.ID),
+		zap.String("event_type", message.EventType),
+		zap.String("payload", message.Payload),
 	)
 	return nil
 }
 
+// OutboxMetrics 接收每次投递的耗时与结果，用于导出发布延迟与失败指标。
+type OutboxMetrics interface {
+	ObservePublish(eventType string, latency time.Duration, err error)
+}
+
+type noopOutboxMetrics struct{}
+
+func (noopOutboxMetrics) ObservePublish(string, time.Duration, error) {}
+
+const DefaultOutboxLeaseDuration = 30 * time.Second
+
+var DefaultOutboxRetryBackoff = retry.Config{
+	InitialDelay:  5 * time.Second,
+	MaxDelay:      10 * time.Minute,
+	BackoffFactor: 2.0,
+	JitterEnabled: true,
+}
+
+// OutboxWorkerConfig 描述 outbox worker 的运行参数。
+type OutboxWorkerConfig struct {
+	PollInterval  time.Duration
+	BatchSize     int
+	MaxRetries    int
+	WorkerID      string
+	LeaseDuration time.Duration
+	// ReaperInterval 控制回收过期租约事件的扫描周期，默认与租约时长一致。
+	ReaperInterval time.Duration
+	Ordering       OutboxOrderingMode
+	// RetryBackoff 决定失败事件下一次投递的时间，复用 retry.ExponentialBackoffWithJitter。
+	RetryBackoff retry.Config
+	// Concurrency 为同时投递的最大事件数，同一聚合的事件始终串行投递。
+	Concurrency int
+	// DrainTimeout 为收到停止信号后等待在途投递完成的上限，默认与租约时长一致。
+	DrainTimeout time.Duration
+	// Metrics 为空时不记录投递指标。
+	Metrics OutboxMetrics
+}
+
+func (c *OutboxWorkerConfig) applyDefaults() {
+	if c.WorkerID == "" {
+		c.WorkerID = defaultWorkerID()
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = DefaultOutboxLeaseDuration
+	}
+	if c.ReaperInterval <= 0 {
+		c.ReaperInterval = c.LeaseDuration
+	}
+	if c.Ordering == "" {
+		c.Ordering = OutboxOrderingNone
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 1
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = c.LeaseDuration
+	}
+	if c.Metrics == nil {
+		c.Metrics = noopOutboxMetrics{}
+	}
+	if c.RetryBackoff.InitialDelay <= 0 {
+		c.RetryBackoff.InitialDelay = DefaultOutboxRetryBackoff.InitialDelay
+	}
+	if c.RetryBackoff.MaxDelay <= 0 {
+		c.RetryBackoff.MaxDelay = DefaultOutboxRetryBackoff.MaxDelay
+	}
+	if c.RetryBackoff.BackoffFactor < 1 {
+		c.RetryBackoff.BackoffFactor = DefaultOutboxRetryBackoff.BackoffFactor
+	}
+}
+
+func (c *OutboxWorkerConfig) validate() error {
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+	if c.MaxRetries <= 0 {
+		return fmt.Errorf("max retries must be positive")
+	}
+	switch c.Ordering {
+	case OutboxOrderingNone, OutboxOrderingPerAggregate:
+	default:
+		return fmt.Errorf("unsupported outbox ordering mode: %s", c.Ordering)
+	}
+	return nil
+}
+
+// defaultWorkerID 生成进程级唯一的租约持有者标识，便于排查事件被哪个副本认领。
+func defaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}
+
 type OutboxWorker struct {
-	repository   *OutboxRepository
-	publisher    OutboxPublisher
-	pollInterval time.Duration
-	batchSize    int
-	maxRetries   int
+	repository *OutboxRepository
+	publisher  OutboxPublisher
+	config     OutboxWorkerConfig
+	recovered  atomic.Int64
 }
 
 func NewOutboxWorker(
 	repository *OutboxRepository,
 	publisher OutboxPublisher,
-	pollInterval time.Duration,
-	batchSize int,
-	maxRetries int,
+	config OutboxWorkerConfig,
 ) (*OutboxWorker, error) {
 	if repository == nil {
 		return nil, fmt.Errorf("outbox repository is required")
@@ -44,27 +178,32 @@ func NewOutboxWorker(
 	if publisher == nil {
 		return nil, fmt.Errorf("outbox publisher is required")
 	}
-	if pollInterval <= 0 {
-		return nil, fmt.Errorf("poll interval must be positive")
-	}
-	if batchSize <= 0 {
-		return nil, fmt.Errorf("batch size must be positive")
-	}
-	if maxRetries <= 0 {
-		return nil, fmt.Errorf("max retries must be positive")
+	config.applyDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
 	}
 
 	return &OutboxWorker{
-		repository:   repository,
-		publisher:    publisher,
-		pollInterval: pollInterval,
-		batchSize:    batchSize,
-		maxRetries:   maxRetries,
+		repository: repository,
+		publisher:  publisher,
+		config:     config,
 	}, nil
 }
+
+func (w *OutboxWorker) WorkerID() string {
+	return w.config.WorkerID
+}
+
+// RecoveredCount 返回本进程启动以来回收的过期事件总数。
+func (w *OutboxWorker) RecoveredCount() int64 {
+	return w.recovered.Load()
+}
+
 func (w *OutboxWorker) Run(ctx context.Context) error {
-	ticker := time.NewTicker(w.pollInterval)
+	ticker := time.NewTicker(w.config.PollInterval)
 	defer ticker.Stop()
+	reaperTicker := time.NewTicker(w.config.ReaperInterval)
+	defer reaperTicker.Stop()
 
 	for {
 		select {
@@ -74,12 +213,67 @@ func (w *OutboxWorker) Run(ctx context.Context) error {
 			if err := w.processBatch(ctx); err != nil {
 				logger.Error("Outbox batch processing failed", zap.Error(err))
 			}
+		case <-reaperTicker.C:
+			if err := w.recoverExpired(ctx); err != nil {
+				logger.Error("Outbox expired event recovery failed", zap.Error(err))
+			}
 		}
 	}
 }
 
+// recoverExpired 回收崩溃或超时 worker 遗留的 PROCESSING 事件，保证至少一次投递。
+func (w *OutboxWorker) recoverExpired(ctx context.Context) error {
+	events, err := w.repository.RecoverExpiredEvents(ctx, w.config.MaxRetries, w.config.BatchSize, w.config.LeaseDuration, w.config.RetryBackoff)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		fields := []zap.Field{
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.EventType),
+			zap.String("previous_owner", event.LeaseOwner),
+			zap.Int("retry_count", event.RetryCount+1),
+		}
+		if event.LeaseExpiresAt != nil {
+			fields = append(fields, zap.Time("lease_expired_at", *event.LeaseExpiresAt))
+		}
+		logger.Warn("Recovered expired outbox event", fields...)
+	}
+
+	if len(events) > 0 {
+		total := w.recovered.Add(int64(len(events)))
+		logger.Info("Outbox expired events recovered",
+			zap.Int("recovered", len(events)),
+			zap.Int64("recovered_total", total),
+		)
+	}
+	return nil
+}
+
+// publish 先将旧版本 payload 经 upcaster 链升级到当前 schema，消费方总是收到最新结构。
+func (w *OutboxWorker) publish(ctx context.Context, event *po.OutboxEventPO) error {
+	message := newOutboxMessage(event)
+	raw, err := w.repository.codecs.Upcast(eventcodec.RawEvent{
+		EventType: message.EventType,
+		Version:   message.EventVersion,
+		Payload:   json.RawMessage(message.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	message.EventType = raw.EventType
+	message.EventVersion = raw.Version
+	message.Payload = string(raw.Payload)
+
+	return w.publisher.Publish(ctx, message)
+}
+
+// processBatch 按聚合分组并发投递一批事件：同一聚合的事件在同一个 goroutine 内按认领顺序串行处理，
+// 在途数量不超过 Concurrency。ctx 取消后不再开始新的投递，已开始的投递在 DrainTimeout 内完成并落库，
+// 未开始的事件交还租约。
 func (w *OutboxWorker) processBatch(ctx context.Context) error {
-	events, err := w.repository.GetPendingEvents(ctx, w.batchSize)
+	events, err := w.repository.ClaimPendingEvents(ctx, w.config.WorkerID, w.config.BatchSize, w.config.LeaseDuration, w.config.Ordering)
 	if err != nil {
 		return err
 	}
@@ -87,33 +281,120 @@ func (w *OutboxWorker) processBatch(ctx context.Context) error {
 		return nil
 	}
 
-	for _, event := range events {
-		if err := w.repository.MarkEventProcessing(ctx, event.ID); err != nil {
-			logger.Warn("Skip outbox event due to lock contention",
-				zap.String("event_id", event.ID),
-				zap.Error(err),
-			)
+	// 投递与状态回写使用独立的 ctx，停止信号不会打断在途投递。
+	drainCtx, cancelDrain := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelDrain()
+	// 计时器先停住，ctx 取消时才开始计时；批次返回时连同 AfterFunc 一起停止，避免计时器滞留。
+	drainTimer := time.AfterFunc(w.config.DrainTimeout, cancelDrain)
+	drainTimer.Stop()
+	stopDrainTimer := context.AfterFunc(ctx, func() {
+		drainTimer.Reset(w.config.DrainTimeout)
+	})
+	defer func() {
+		stopDrainTimer()
+		drainTimer.Stop()
+	}()
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		unattended []string
+	)
+	slots := make(chan struct{}, w.config.Concurrency)
+	for _, group := range groupByAggregate(events) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			for _, event := range group {
+				unattended = append(unattended, event.ID)
+			}
+			mu.Unlock()
 			continue
 		}
 
-		if err := w.publisher.Publish(ctx, event.EventType, event.Payload); err != nil {
-			failErr := w.repository.MarkEventFailed(ctx, event.ID, w.maxRetries)
-			if failErr != nil {
-				logger.Error("Failed to mark outbox event as failed",
-					zap.String("event_id", event.ID),
-					zap.Error(failErr),
-				)
+		wg.Add(1)
+		go func(group []*po.OutboxEventPO) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			for i, event := range group {
+				if ctx.Err() != nil {
+					mu.Lock()
+					for _, rest := range group[i:] {
+						unattended = append(unattended, rest.ID)
+					}
+					mu.Unlock()
+					return
+				}
+				w.processEvent(drainCtx, event)
 			}
-			continue
+		}(group)
+	}
+	wg.Wait()
+
+	if len(unattended) > 0 {
+		// 在途投递可能已耗尽 DrainTimeout，交还租约不受其影响。
+		released, err := w.repository.ReleaseEvents(context.WithoutCancel(ctx), w.config.WorkerID, unattended)
+		if err != nil {
+			return err
 		}
+		logger.Info("Released unprocessed outbox events on shutdown", zap.Int64("released", released))
+	}
+	return nil
+}
 
-		if err := w.repository.MarkEventPublished(ctx, event.ID); err != nil {
-			logger.Error("Failed to mark outbox event as published",
-				zap.String("event_id", event.ID),
-				zap.Error(err),
-			)
+func (w *OutboxWorker) processEvent(ctx context.Context, event *po.OutboxEventPO) {
+	start := time.Now()
+	err := w.publish(ctx, event)
+	w.config.Metrics.ObservePublish(event.EventType, time.Since(start), err)
+	if err != nil {
+		logger.Warn("Outbox event publish failed",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.EventType),
+			zap.Int("retry_count", event.RetryCount),
+			zap.Error(err),
+		)
+		failErr := w.repository.MarkEventFailed(ctx, w.config.WorkerID, event.ID, err, w.config.MaxRetries, w.config.RetryBackoff)
+		if failErr != nil {
+			w.logMarkError("Failed to mark outbox event as failed", event, failErr)
 		}
+		return
 	}
 
-	return nil
+	if err := w.repository.MarkEventPublished(ctx, w.config.WorkerID, event.ID); err != nil {
+		w.logMarkError("Failed to mark outbox event as published", event, err)
+	}
+}
+
+// logMarkError 记录投递结果写回失败；租约丢失是预期内的竞争，只记警告。
+func (w *OutboxWorker) logMarkError(message string, event *po.OutboxEventPO, err error) {
+	if errors.Is(err, ErrOutboxLeaseLost) {
+		logger.Warn("Outbox event lease lost before the result was recorded, leaving it to the current holder",
+			zap.String("event_id", event.ID),
+			zap.String("worker_id", w.config.WorkerID),
+		)
+		return
+	}
+	logger.Error(message,
+		zap.String("event_id", event.ID),
+		zap.Error(err),
+	)
+}
+
+// groupByAggregate 按聚合拆分事件，保留认领时的相对顺序。
+func groupByAggregate(events []*po.OutboxEventPO) [][]*po.OutboxEventPO {
+	index := make(map[string]int)
+	var groups [][]*po.OutboxEventPO
+	for _, event := range events {
+		i, ok := index[event.AggregateID]
+		if !ok {
+			i = len(groups)
+			index[event.AggregateID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], event)
+	}
+	return groups
 }
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"ddd/infrastructure/persistence/mysql/po"
+)
+
+func TestGroupByAggregateKeepsOrderWithinAggregate(t *testing.T) {
+	events := []*po.OutboxEventPO{
+		{ID: "a1", AggregateID: "order-a"},
+		{ID: "b1", AggregateID: "order-b"},
+		{ID: "a2", AggregateID: "order-a"},
+		{ID: "c1", AggregateID: "order-c"},
+		{ID: "b2", AggregateID: "order-b"},
+		{ID: "a3", AggregateID: "order-a"},
+	}
+
+	var got [][]string
+	for _, group := range groupByAggregate(events) {
+		var ids []string
+		for _, event := range group {
+			ids = append(ids, event.ID)
+		}
+		got = append(got, ids)
+	}
+
+	want := [][]string{{"a1", "a2", "a3"}, {"b1", "b2"}, {"c1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByAggregate() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+package po
+
+import "time"
+
+// StoredEventPO 是事件存储中的一条事件，只追加不修改。
+// (aggregate_id, version) 唯一，是事件流乐观并发控制的依据；position 为全局递增序号。
+type StoredEventPO struct {
+	Position      int64     `gorm:"primaryKey;autoIncrement"`
+	EventID       string    `gorm:"size:64;uniqueIndex:uk_event_store_event_id;not null"`
+	AggregateID   string    `gorm:"size:64;uniqueIndex:uk_event_store_aggregate_version,priority:1;not null"`
+	AggregateType string    `gorm:"size:50;index;not null"`
+	Version       int       `gorm:"uniqueIndex:uk_event_store_aggregate_version,priority:2;not null"`
+	EventType     string    `gorm:"size:100;index;not null"`
+	EventVersion  int       `gorm:"default:1;not null"`
+	Payload       string    `gorm:"type:json;not null"`
+	OccurredOn    time.Time `gorm:"type:datetime(3);not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (StoredEventPO) TableName() string {
+	return "event_store"
+}
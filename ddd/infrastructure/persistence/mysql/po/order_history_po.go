@@ -0,0 +1,18 @@
+package po
+
+import "time"
+
+// OrderHistoryPO 是订单每次保存后的完整状态，payload 与订单快照同构，按 schema_version 解码。
+type OrderHistoryPO struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	OrderID       string    `gorm:"size:64;not null;index:idx_order_history_order_recorded,priority:1"`
+	Version       int       `gorm:"not null"`
+	Status        string    `gorm:"size:20;not null"`
+	SchemaVersion int       `gorm:"not null"`
+	Payload       string    `gorm:"type:json;not null"`
+	RecordedAt    time.Time `gorm:"type:datetime(3);not null;index:idx_order_history_order_recorded,priority:2"`
+}
+
+func (OrderHistoryPO) TableName() string {
+	return "order_history"
+}
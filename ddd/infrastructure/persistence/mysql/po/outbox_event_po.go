@@ -10,14 +10,20 @@ import (
 )
 
 type OutboxEventPO struct {
-	ID          string    `gorm:"primaryKey;size:64"`
-	AggregateID string    `gorm:"size:64;index;not null"`
-	EventType   string    `gorm:"size:100;index;not null"`
-	Payload     string    `gorm:"type:json;not null"`
-	Status      string    `gorm:"size:20;default:PENDING;not null"`
-	RetryCount  int       `gorm:"default:0;not null"`
-	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	ID                string     `gorm:"primaryKey;size:64"`
+	AggregateID       string     `gorm:"size:64;index;uniqueIndex:uk_outbox_events_aggregate_sequence,priority:1;not null"`
+	AggregateSequence int64      `gorm:"uniqueIndex:uk_outbox_events_aggregate_sequence,priority:2;default:0;not null"`
+	EventType         string     `gorm:"size:100;index;not null"`
+	EventVersion      int        `gorm:"default:1;not null"`
+	Payload           string     `gorm:"type:json;not null"`
+	Status            string     `gorm:"size:20;default:PENDING;not null"`
+	RetryCount        int        `gorm:"default:0;not null"`
+	NextAttemptAt     *time.Time `gorm:"index"`
+	LastError         string     `gorm:"type:text"`
+	LeaseOwner        string     `gorm:"size:128"`
+	LeaseExpiresAt    *time.Time `gorm:"index"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime;index"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime"`
 }
 
 func (OutboxEventPO) TableName() string {
@@ -31,57 +37,104 @@ const (
 	EventStatusProcessing EventStatus = "PROCESSING"
 	EventStatusPublished  EventStatus = "PUBLISHED"
 	EventStatusFailed     EventStatus = "FAILED"
+	EventStatusDiscarded  EventStatus = "DISCARDED"
 )
 
-func FromDomainEvent(event shared.DomainEvent) (*OutboxEventPO, error) {
-	payload, err := serializeEventToJSON(event)
-	if err != nil {
-		return nil, err
-	}
-	eventID := uuid.Must(uuid.NewV7()).String()
+// OutboxEventArchivePO 保存超出保留期后从 outbox_events 迁出的已发布事件。
+type OutboxEventArchivePO struct {
+	ID                string    `gorm:"primaryKey;size:64"`
+	AggregateID       string    `gorm:"size:64;index;not null"`
+	AggregateSequence int64     `gorm:"default:0;not null"`
+	EventType         string    `gorm:"size:100;index;not null"`
+	EventVersion      int       `gorm:"default:1;not null"`
+	Payload           string    `gorm:"type:json;not null"`
+	Status            string    `gorm:"size:20;not null"`
+	RetryCount        int       `gorm:"default:0;not null"`
+	LastError         string    `gorm:"type:text"`
+	CreatedAt         time.Time `gorm:"index"`
+	UpdatedAt         time.Time
+	ArchivedAt        time.Time `gorm:"autoCreateTime"`
+}
 
-	return &OutboxEventPO{
-		ID:          eventID,
-		AggregateID: event.GetAggregateID(),
-		EventType:   event.EventName(),
-		Payload:     payload,
-		Status:      string(EventStatusPending),
-		RetryCount:  0,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}, nil
+func (OutboxEventArchivePO) TableName() string {
+	return "outbox_events_archive"
 }
-func serializeEventToJSON(event shared.DomainEvent) (string, error) {
-	eventData := map[string]interface{}{
-		"event_name":   event.EventName(),
-		"aggregate_id": event.GetAggregateID(),
-		"occurred_on":  event.OccurredOn(),
+
+func (po *OutboxEventPO) ToArchive() OutboxEventArchivePO {
+	return OutboxEventArchivePO{
+		ID:                po.ID,
+		AggregateID:       po.AggregateID,
+		AggregateSequence: po.AggregateSequence,
+		EventType:         po.EventType,
+		EventVersion:      po.EventVersion,
+		Payload:           po.Payload,
+		Status:            po.Status,
+		RetryCount:        po.RetryCount,
+		LastError:         po.LastError,
+		CreatedAt:         po.CreatedAt,
+		UpdatedAt:         po.UpdatedAt,
 	}
-	if orderEvent, ok := event.(interface{ OrderID() string }); ok {
-		eventData["order_id"] = orderEvent.OrderID()
-		if userIDGetter, ok := event.(interface{ UserID() string }); ok {
-			eventData["user_id"] = userIDGetter.UserID()
-		}
-		if totalAmountGetter, ok := event.(interface{ TotalAmount() shared.Money }); ok {
-			money := totalAmountGetter.TotalAmount()
-			eventData["total_amount"] = money.Amount()
-			eventData["total_currency"] = money.Currency()
-		}
-	} else if userEvent, ok := event.(interface{ UserID() string }); ok {
-		eventData["user_id"] = userEvent.UserID()
-		if nameGetter, ok := event.(interface{ Name() string }); ok {
-			eventData["name"] = nameGetter.Name()
-		}
-		if emailGetter, ok := event.(interface{ Email() string }); ok {
-			eventData["email"] = emailGetter.Email()
-		}
+}
+
+// OutboxEventAuditPO 记录对 outbox 事件的人工处置（重放、丢弃）。
+type OutboxEventAuditPO struct {
+	ID        string    `gorm:"primaryKey;size:64"`
+	EventID   string    `gorm:"size:64;index;not null"`
+	Action    string    `gorm:"size:20;not null"`
+	Operator  string    `gorm:"size:100;not null"`
+	Note      string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (OutboxEventAuditPO) TableName() string {
+	return "outbox_event_audits"
+}
+
+// ProcessedEventPO 记录某个消费者已处理过的 outbox 事件，(event_id, consumer) 唯一。
+type ProcessedEventPO struct {
+	EventID     string    `gorm:"primaryKey;size:64"`
+	Consumer    string    `gorm:"primaryKey;size:100"`
+	EventType   string    `gorm:"size:100;not null"`
+	ProcessedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+func (ProcessedEventPO) TableName() string {
+	return "processed_events"
+}
+
+const (
+	AuditActionReplay  = "REPLAY"
+	AuditActionDiscard = "DISCARD"
+)
+
+// FromDomainEvent 以序列化后的 payload 构建待发布的 outbox 记录。
+func FromDomainEvent(event shared.DomainEvent, payload []byte, version int) *OutboxEventPO {
+	now := time.Now()
+	return &OutboxEventPO{
+		ID:           uuid.Must(uuid.NewV7()).String(),
+		AggregateID:  event.GetAggregateID(),
+		EventType:    event.EventName(),
+		EventVersion: version,
+		Payload:      string(payload),
+		Status:       string(EventStatusPending),
+		RetryCount:   0,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
-	data, err := json.Marshal(eventData)
-	if err != nil {
-		return "", err
+}
+func (po *OutboxEventPO) ToDeadLetter() *shared.DeadLetterEvent {
+	return &shared.DeadLetterEvent{
+		ID:                po.ID,
+		AggregateID:       po.AggregateID,
+		AggregateSequence: po.AggregateSequence,
+		EventType:         po.EventType,
+		EventVersion:      po.EventVersion,
+		Payload:           po.Payload,
+		RetryCount:        po.RetryCount,
+		LastError:         po.LastError,
+		CreatedAt:         po.CreatedAt,
+		UpdatedAt:         po.UpdatedAt,
 	}
-
-	return string(data), nil
 }
 func (po *OutboxEventPO) ToEventData() (map[string]interface{}, error) {
 	var data map[string]interface{}
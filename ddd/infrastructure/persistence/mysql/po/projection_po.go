@@ -0,0 +1,56 @@
+package po
+
+import "time"
+
+// ProjectionCheckpointPO 记录投影已处理到的事件源位置，与读模型在同一事务内更新。
+type ProjectionCheckpointPO struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	Source    string    `gorm:"size:20;not null"`
+	Position  string    `gorm:"size:64;not null;default:''"`
+	Processed int64     `gorm:"not null;default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProjectionCheckpointPO) TableName() string {
+	return "projection_checkpoints"
+}
+
+// UserSpendingSummaryPO 是用户消费汇总投影的读模型，每个用户每种币种一行。
+type UserSpendingSummaryPO struct {
+	UserID          string    `gorm:"primaryKey;size:64"`
+	Currency        string    `gorm:"primaryKey;size:3"`
+	OrderCount      int64     `gorm:"not null;default:0"`
+	DeliveredOrders int64     `gorm:"not null;default:0"`
+	TotalSpent      int64     `gorm:"not null;default:0"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (UserSpendingSummaryPO) TableName() string {
+	return "user_spending_summary"
+}
+
+// UserSpendingOrderPO 是用户消费汇总投影的工作表，记录订单归属与金额，供签收时累加。
+type UserSpendingOrderPO struct {
+	OrderID     string `gorm:"primaryKey;size:64"`
+	UserID      string `gorm:"size:64;not null"`
+	Currency    string `gorm:"size:3;not null"`
+	PlacedTotal int64  `gorm:"not null"`
+	ItemsTotal  int64  `gorm:"not null;default:0"`
+	ItemEvents  int    `gorm:"not null;default:0"`
+	Status      string `gorm:"size:20;not null"`
+}
+
+func (UserSpendingOrderPO) TableName() string {
+	return "user_spending_orders"
+}
+
+// UserSpendingOrderItemPO 记录订单项小计，订单项被移除时据此扣减订单金额。
+type UserSpendingOrderItemPO struct {
+	ItemID   string `gorm:"primaryKey;size:128"`
+	OrderID  string `gorm:"size:64;index;not null"`
+	Subtotal int64  `gorm:"not null"`
+}
+
+func (UserSpendingOrderItemPO) TableName() string {
+	return "user_spending_order_items"
+}
@@ -0,0 +1,25 @@
+package po
+
+import "time"
+
+// SagaInstancePO 是 saga 实例的持久化状态。Step 在推进时指向下一个要执行的步骤，
+// 在补偿时指向下一个要撤销的步骤；AbortReason 非空表示实例需要（或正在）补偿。
+type SagaInstancePO struct {
+	ID            string    `gorm:"primaryKey;size:36"`
+	SagaType      string    `gorm:"size:50;not null;uniqueIndex:uk_saga_instances_type_correlation,priority:1"`
+	CorrelationID string    `gorm:"size:64;not null;uniqueIndex:uk_saga_instances_type_correlation,priority:2"`
+	Status        string    `gorm:"size:20;not null;index:idx_saga_instances_status_next_run,priority:1"`
+	Step          int       `gorm:"not null;default:0"`
+	Attempts      int       `gorm:"not null;default:0"`
+	Data          string    `gorm:"type:json;not null"`
+	AbortReason   string    `gorm:"size:255;not null;default:''"`
+	LastError     string    `gorm:"type:text"`
+	LeaseOwner    string    `gorm:"size:100;not null;default:''"`
+	NextRunAt     time.Time `gorm:"type:datetime(3);not null;index:idx_saga_instances_status_next_run,priority:2"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (SagaInstancePO) TableName() string {
+	return "saga_instances"
+}
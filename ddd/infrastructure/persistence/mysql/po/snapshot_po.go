@@ -0,0 +1,17 @@
+package po
+
+import "time"
+
+// AggregateSnapshotPO 保存聚合最近一次快照，每个聚合一行，新快照覆盖旧快照。
+type AggregateSnapshotPO struct {
+	AggregateID   string    `gorm:"primaryKey;size:64"`
+	AggregateType string    `gorm:"size:50;index;not null"`
+	Version       int       `gorm:"not null"`
+	SchemaVersion int       `gorm:"not null"`
+	Payload       string    `gorm:"type:json;not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (AggregateSnapshotPO) TableName() string {
+	return "aggregate_snapshots"
+}
@@ -0,0 +1,291 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Projection 是由领域事件维护的读模型。
+// Apply 与 Reset 收到的 ctx 携带事务（persistence.TxFromContext），读模型的写入与检查点一起提交，
+// 因此每个事件对每个投影恰好生效一次。
+type Projection interface {
+	Name() string
+	// Apply 将一个事件应用到读模型，不关心的事件直接返回 nil。
+	Apply(ctx context.Context, event shared.DomainEvent) error
+	// Reset 清空读模型，重建前调用。
+	Reset(ctx context.Context) error
+}
+
+// SourcedEvent 是事件源中的一条事件及其位置。
+type SourcedEvent struct {
+	Position string
+	Event    shared.DomainEvent
+}
+
+// ProjectionSource 按位置升序提供事件，位置为空字符串表示从头开始。
+type ProjectionSource interface {
+	Name() string
+	Read(ctx context.Context, after string, limit int) ([]SourcedEvent, error)
+}
+
+type ProjectionEngineConfig struct {
+	BatchSize    int
+	PollInterval time.Duration
+}
+
+var DefaultProjectionEngineConfig = ProjectionEngineConfig{
+	BatchSize:    200,
+	PollInterval: 2 * time.Second,
+}
+
+// ProjectionStatus 是投影的检查点快照。
+type ProjectionStatus struct {
+	Name      string
+	Source    string
+	Position  string
+	Processed int64
+	UpdatedAt time.Time
+}
+
+// ProjectionEngine 依次驱动已注册的投影追赶事件源。每批事件在一个事务内应用，
+// 事务开始时以 FOR UPDATE 锁住投影的检查点行，多个进程同时运行时同一投影的批次被串行化。
+type ProjectionEngine struct {
+	db          *gorm.DB
+	source      ProjectionSource
+	config      ProjectionEngineConfig
+	mu          sync.RWMutex
+	projections []Projection
+}
+
+func NewProjectionEngine(db *gorm.DB, source ProjectionSource, config ProjectionEngineConfig) *ProjectionEngine {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultProjectionEngineConfig.BatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultProjectionEngineConfig.PollInterval
+	}
+	return &ProjectionEngine{db: db, source: source, config: config}
+}
+
+// Register 注册投影，名称即检查点主键，必须唯一。
+func (e *ProjectionEngine) Register(projection Projection) error {
+	if projection == nil || projection.Name() == "" {
+		return fmt.Errorf("projection with a non-empty name is required")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, existing := range e.projections {
+		if existing.Name() == projection.Name() {
+			return fmt.Errorf("projection %s is already registered", projection.Name())
+		}
+	}
+	e.projections = append(e.projections, projection)
+	return nil
+}
+
+func (e *ProjectionEngine) lookup(name string) (Projection, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, projection := range e.projections {
+		if projection.Name() == name {
+			return projection, nil
+		}
+	}
+	return nil, fmt.Errorf("projection %s is not registered", name)
+}
+
+// Run 按 PollInterval 轮询，直到 ctx 取消。单个投影失败只记录日志，下一轮从检查点重试。
+func (e *ProjectionEngine) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		e.mu.RLock()
+		projections := append([]Projection(nil), e.projections...)
+		e.mu.RUnlock()
+
+		for _, projection := range projections {
+			if _, err := e.catchUp(ctx, projection); err != nil && ctx.Err() == nil {
+				logger.Error("Projection failed, will retry from checkpoint",
+					zap.String("projection", projection.Name()),
+					zap.Error(err),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CatchUp 处理指定投影的全部积压事件，返回本次处理的事件数。
+func (e *ProjectionEngine) CatchUp(ctx context.Context, name string) (int64, error) {
+	projection, err := e.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return e.catchUp(ctx, projection)
+}
+
+// Rebuild 清空读模型并把检查点重置到事件源起点，然后从头追赶。
+// 重置与清空在同一事务内完成；追赶完成前读模型只反映部分历史。
+func (e *ProjectionEngine) Rebuild(ctx context.Context, name string) (int64, error) {
+	projection, err := e.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	err = e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		checkpoint, err := e.lockCheckpoint(tx, projection.Name())
+		if err != nil {
+			return err
+		}
+		if err := projection.Reset(persistence.ContextWithTx(ctx, tx)); err != nil {
+			return fmt.Errorf("failed to reset projection %s: %w", projection.Name(), err)
+		}
+		return tx.Model(checkpoint).Updates(map[string]any{
+			"source":    e.source.Name(),
+			"position":  "",
+			"processed": 0,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	logger.Info("Projection reset, replaying from the beginning",
+		zap.String("projection", projection.Name()),
+		zap.String("source", e.source.Name()),
+	)
+	return e.catchUp(ctx, projection)
+}
+
+// Status 返回所有已注册投影的检查点，尚未运行过的投影位置为空。
+func (e *ProjectionEngine) Status(ctx context.Context) ([]ProjectionStatus, error) {
+	e.mu.RLock()
+	projections := append([]Projection(nil), e.projections...)
+	e.mu.RUnlock()
+
+	names := make([]string, len(projections))
+	for i, projection := range projections {
+		names[i] = projection.Name()
+	}
+	var checkpoints []po.ProjectionCheckpointPO
+	if err := e.db.WithContext(ctx).Where("name IN ?", names).Find(&checkpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to load projection checkpoints: %w", err)
+	}
+	byName := make(map[string]po.ProjectionCheckpointPO, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		byName[checkpoint.Name] = checkpoint
+	}
+
+	statuses := make([]ProjectionStatus, len(names))
+	for i, name := range names {
+		checkpoint, ok := byName[name]
+		if !ok {
+			statuses[i] = ProjectionStatus{Name: name, Source: e.source.Name()}
+			continue
+		}
+		statuses[i] = ProjectionStatus{
+			Name:      name,
+			Source:    checkpoint.Source,
+			Position:  checkpoint.Position,
+			Processed: checkpoint.Processed,
+			UpdatedAt: checkpoint.UpdatedAt,
+		}
+	}
+	return statuses, nil
+}
+
+func (e *ProjectionEngine) catchUp(ctx context.Context, projection Projection) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		processed, err := e.processBatch(ctx, projection)
+		total += processed
+		if err != nil {
+			return total, err
+		}
+		if processed < int64(e.config.BatchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (e *ProjectionEngine) processBatch(ctx context.Context, projection Projection) (int64, error) {
+	var processed int64
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		checkpoint, err := e.lockCheckpoint(tx, projection.Name())
+		if err != nil {
+			return err
+		}
+		if checkpoint.Position != "" && checkpoint.Source != e.source.Name() {
+			return fmt.Errorf("projection %s was built from %s, rebuild it to switch to %s",
+				projection.Name(), checkpoint.Source, e.source.Name())
+		}
+
+		events, err := e.source.Read(ctx, checkpoint.Position, e.config.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		txCtx := persistence.ContextWithTx(ctx, tx)
+		for _, sourced := range events {
+			if err := projection.Apply(txCtx, sourced.Event); err != nil {
+				return fmt.Errorf("projection %s failed at %s (%s): %w",
+					projection.Name(), sourced.Position, sourced.Event.EventName(), err)
+			}
+		}
+		processed = int64(len(events))
+		return tx.Model(checkpoint).Updates(map[string]any{
+			"source":    e.source.Name(),
+			"position":  events[len(events)-1].Position,
+			"processed": gorm.Expr("processed + ?", processed),
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return processed, nil
+}
+
+// lockCheckpoint 锁住投影的检查点行，不存在时先创建。
+func (e *ProjectionEngine) lockCheckpoint(tx *gorm.DB, name string) (*po.ProjectionCheckpointPO, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&po.ProjectionCheckpointPO{Name: name, Source: e.source.Name()}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create projection checkpoint: %w", err)
+	}
+
+	var checkpoint po.ProjectionCheckpointPO
+	err = tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("name = ?", name).
+		First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("projection checkpoint %s disappeared", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock projection checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
@@ -0,0 +1,141 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"ddd/infrastructure/eventcodec"
+	"ddd/infrastructure/persistence/mysql/po"
+
+	"gorm.io/gorm"
+)
+
+// DefaultProjectionSettleDelay 是投影读取事件时跳过的最近时间窗口。
+// 位置在写入时分配、在提交时才可见，晚提交的事务可能带着更小的位置出现；
+// 只读取早于该窗口的事件，避免检查点越过这类事件。窗口应大于最长写事务的耗时。
+const DefaultProjectionSettleDelay = 5 * time.Second
+
+// OutboxProjectionSource 以 outbox_events 与 outbox_events_archive 为事件源，
+// 位置为 outbox 事件 ID（UUIDv7，按字典序即按生成时间排序），不区分投递状态。
+// 已被保留任务删除且未归档到表中的事件无法参与重建。
+type OutboxProjectionSource struct {
+	db          *gorm.DB
+	codecs      *eventcodec.Registry
+	settleDelay time.Duration
+}
+
+func NewOutboxProjectionSource(db *gorm.DB, settleDelay time.Duration) *OutboxProjectionSource {
+	return &OutboxProjectionSource{db: db, codecs: eventcodec.Default(), settleDelay: settleDelay}
+}
+
+func (s *OutboxProjectionSource) Name() string { return "outbox" }
+
+func (s *OutboxProjectionSource) Read(ctx context.Context, after string, limit int) ([]SourcedEvent, error) {
+	cutoff := time.Now().Add(-s.settleDelay)
+
+	var live []po.OutboxEventPO
+	err := s.db.WithContext(ctx).
+		Where("id > ? AND created_at <= ?", after, cutoff).
+		Order("id ASC").
+		Limit(limit).
+		Find(&live).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox events: %w", err)
+	}
+	var archived []po.OutboxEventArchivePO
+	err = s.db.WithContext(ctx).
+		Where("id > ? AND created_at <= ?", after, cutoff).
+		Order("id ASC").
+		Limit(limit).
+		Find(&archived).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived outbox events: %w", err)
+	}
+
+	// 两次查询之间保留任务可能把同一事件移入归档表，合并时按 ID 去重。
+	seen := make(map[string]struct{}, len(live)+len(archived))
+	messages := make([]OutboxMessage, 0, len(live)+len(archived))
+	for i := range live {
+		seen[live[i].ID] = struct{}{}
+		messages = append(messages, newOutboxMessage(&live[i]))
+	}
+	for _, row := range archived {
+		if _, ok := seen[row.ID]; ok {
+			continue
+		}
+		messages = append(messages, OutboxMessage{
+			ID:                row.ID,
+			AggregateID:       row.AggregateID,
+			AggregateSequence: row.AggregateSequence,
+			EventType:         row.EventType,
+			EventVersion:      row.EventVersion,
+			Payload:           row.Payload,
+			CreatedAt:         row.CreatedAt,
+		})
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	events := make([]SourcedEvent, len(messages))
+	for i, message := range messages {
+		event, err := message.Decode(s.codecs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode outbox event %s: %w", message.ID, err)
+		}
+		events[i] = SourcedEvent{Position: message.ID, Event: event}
+	}
+	return events, nil
+}
+
+// EventStoreProjectionSource 以 event_store 为事件源，位置为全局自增的 position。
+type EventStoreProjectionSource struct {
+	store       *EventStore
+	settleDelay time.Duration
+}
+
+func NewEventStoreProjectionSource(db *gorm.DB, settleDelay time.Duration) *EventStoreProjectionSource {
+	return &EventStoreProjectionSource{store: NewEventStore(db), settleDelay: settleDelay}
+}
+
+func (s *EventStoreProjectionSource) Name() string { return "event_store" }
+
+func (s *EventStoreProjectionSource) Read(ctx context.Context, after string, limit int) ([]SourcedEvent, error) {
+	var position int64
+	if after != "" {
+		var err error
+		position, err = strconv.ParseInt(after, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event store position %q: %w", after, err)
+		}
+	}
+
+	var rows []po.StoredEventPO
+	err := s.store.db.WithContext(ctx).
+		Where("position > ? AND created_at <= ?", position, time.Now().Add(-s.settleDelay)).
+		Order("position ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event store: %w", err)
+	}
+
+	decoded, err := s.store.decode(rows)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]SourcedEvent, len(rows))
+	for i, row := range rows {
+		events[i] = SourcedEvent{Position: strconv.FormatInt(row.Position, 10), Event: decoded[i]}
+	}
+	return events, nil
+}
+
+var (
+	_ ProjectionSource = (*OutboxProjectionSource)(nil)
+	_ ProjectionSource = (*EventStoreProjectionSource)(nil)
+)
@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"ddd/domain/order"
+	"ddd/infrastructure/eventcodec"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOutboxProjectionSourceMergesLiveAndArchive(t *testing.T) {
+	db, mock := newMockDB(t)
+	source := NewOutboxProjectionSource(db, 0)
+
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	encoded, err := eventcodec.Default().Encode(order.RebuildOrderConfirmedEvent("order-1", createdAt))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	columns := []string{"id", "aggregate_id", "event_type", "event_version", "payload", "created_at"}
+	row := func(rows *sqlmock.Rows, id string) *sqlmock.Rows {
+		return rows.AddRow(id, "order-1", encoded.EventType, encoded.Version, string(encoded.Payload), createdAt)
+	}
+
+	// e2 在两次查询之间被归档，同时出现在两张表中。
+	mock.ExpectQuery("SELECT \\* FROM `outbox_events` WHERE id > \\?").
+		WithArgs("e0", sqlmock.AnyArg(), 3).
+		WillReturnRows(row(row(row(sqlmock.NewRows(columns), "e2"), "e4"), "e5"))
+	mock.ExpectQuery("SELECT \\* FROM `outbox_events_archive` WHERE id > \\?").
+		WithArgs("e0", sqlmock.AnyArg(), 3).
+		WillReturnRows(row(row(row(sqlmock.NewRows(columns), "e1"), "e2"), "e3"))
+
+	events, err := source.Read(context.Background(), "e0", 3)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var positions []string
+	for _, event := range events {
+		positions = append(positions, event.Position)
+	}
+	if want := []string{"e1", "e2", "e3"}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("Read() positions = %v, want %v", positions, want)
+	}
+}
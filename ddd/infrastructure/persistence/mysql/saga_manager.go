@@ -0,0 +1,655 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/infrastructure/persistence/retry"
+	"ddd/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// saga 实例状态。
+const (
+	SagaStatusRunning      = "RUNNING"
+	SagaStatusCompensating = "COMPENSATING"
+	SagaStatusCompleted    = "COMPLETED"
+	SagaStatusCompensated  = "COMPENSATED"
+	SagaStatusFailed       = "FAILED"
+)
+
+// SagaIntakeName 是 saga 接收事件时使用的投影检查点名称。
+const SagaIntakeName = "sagas"
+
+// DefaultSagaStepTimeout 用于未设置 Timeout 的步骤。
+const DefaultSagaStepTimeout = 30 * time.Second
+
+type SagaManagerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxStartAge 为启动事件的最大年龄，更早的事件不再启动 saga，
+	// 避免首次部署或长时间停机后为历史数据补跑流程。为 0 时不限制。
+	MaxStartAge time.Duration
+	// CompensationMaxAttempts 为单个补偿步骤的最大尝试次数，用尽后实例转为 FAILED 等待人工处理。
+	CompensationMaxAttempts int
+	// LeaseGrace 为步骤超时之外额外保留的租约时间，租约过期的实例会被其他副本重新认领。
+	LeaseGrace   time.Duration
+	RetryBackoff retry.Config
+	Owner        string
+}
+
+var DefaultSagaManagerConfig = SagaManagerConfig{
+	PollInterval:            time.Second,
+	BatchSize:               20,
+	MaxStartAge:             time.Hour,
+	CompensationMaxAttempts: 10,
+	LeaseGrace:              30 * time.Second,
+	RetryBackoff: retry.Config{
+		InitialDelay:  time.Second,
+		MaxDelay:      5 * time.Minute,
+		BackoffFactor: 2.0,
+		JitterEnabled: true,
+	},
+}
+
+// SagaInstance 是 saga 实例的只读视图，供运维命令展示。
+type SagaInstance struct {
+	ID            string
+	Type          string
+	CorrelationID string
+	Status        string
+	Step          string
+	Attempts      int
+	AbortReason   string
+	LastError     string
+	NextRunAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type SagaFilter struct {
+	Type          string
+	Status        string
+	CorrelationID string
+	Limit         int
+}
+
+// SagaManager 持久化并驱动 saga 实例。
+//
+// 事件经 ProjectionEngine 进入（SagaManager 实现了 Projection）：启动事件在检查点所在事务内
+// 创建实例，中止事件为运行中的实例记录中止原因。Run 轮询到期的实例，以 FOR UPDATE SKIP LOCKED
+// 认领并写入租约，在事务外按步骤超时执行一个步骤，再校验租约后保存结果。步骤失败按退避重试，
+// 用尽次数或被拒绝（shared.ErrSagaStepRejected）后逆序补偿已完成的步骤。
+type SagaManager struct {
+	db          *gorm.DB
+	config      SagaManagerConfig
+	mu          sync.RWMutex
+	definitions []shared.SagaDefinition
+}
+
+func NewSagaManager(db *gorm.DB, config SagaManagerConfig) *SagaManager {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultSagaManagerConfig.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultSagaManagerConfig.BatchSize
+	}
+	if config.CompensationMaxAttempts <= 0 {
+		config.CompensationMaxAttempts = DefaultSagaManagerConfig.CompensationMaxAttempts
+	}
+	if config.LeaseGrace <= 0 {
+		config.LeaseGrace = DefaultSagaManagerConfig.LeaseGrace
+	}
+	if config.RetryBackoff.InitialDelay <= 0 {
+		config.RetryBackoff.InitialDelay = DefaultSagaManagerConfig.RetryBackoff.InitialDelay
+	}
+	if config.RetryBackoff.MaxDelay <= 0 {
+		config.RetryBackoff.MaxDelay = DefaultSagaManagerConfig.RetryBackoff.MaxDelay
+	}
+	if config.RetryBackoff.BackoffFactor < 1 {
+		config.RetryBackoff.BackoffFactor = DefaultSagaManagerConfig.RetryBackoff.BackoffFactor
+	}
+	if config.Owner == "" {
+		config.Owner = defaultWorkerID()
+	}
+	return &SagaManager{db: db, config: config}
+}
+
+// Register 注册 saga 定义，类型名必须唯一。
+func (m *SagaManager) Register(definition shared.SagaDefinition) error {
+	if err := definition.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.definitions {
+		if existing.Type == definition.Type {
+			return fmt.Errorf("saga %s is already registered", definition.Type)
+		}
+	}
+	m.definitions = append(m.definitions, definition)
+	return nil
+}
+
+func (m *SagaManager) registered() []shared.SagaDefinition {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]shared.SagaDefinition(nil), m.definitions...)
+}
+
+func (m *SagaManager) definition(sagaType string) (shared.SagaDefinition, bool) {
+	for _, definition := range m.registered() {
+		if definition.Type == sagaType {
+			return definition, true
+		}
+	}
+	return shared.SagaDefinition{}, false
+}
+
+func (m *SagaManager) getDB(ctx context.Context) *gorm.DB {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return m.db.WithContext(ctx)
+}
+
+func (m *SagaManager) Name() string { return SagaIntakeName }
+
+// Apply 启动或中止 saga 实例，ctx 携带投影引擎的事务。
+func (m *SagaManager) Apply(ctx context.Context, event shared.DomainEvent) error {
+	db := m.getDB(ctx)
+	for _, definition := range m.registered() {
+		if definition.StartOn == event.EventName() {
+			if err := m.start(db, definition, event); err != nil {
+				return err
+			}
+		}
+		if definition.AbortsOn(event.EventName()) {
+			if err := m.abort(db, definition, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reset 拒绝重建：从头重放会为历史事件再次启动 saga 并重复执行命令。
+func (m *SagaManager) Reset(context.Context) error {
+	return fmt.Errorf("saga intake cannot be rebuilt, it would start sagas for past events again")
+}
+
+func (m *SagaManager) start(db *gorm.DB, definition shared.SagaDefinition, event shared.DomainEvent) error {
+	if m.config.MaxStartAge > 0 && time.Since(event.OccurredOn()) > m.config.MaxStartAge {
+		logger.Info("Start event is older than max start age, saga not started",
+			zap.String("saga_type", definition.Type),
+			zap.String("correlation_id", event.GetAggregateID()),
+			zap.Time("occurred_on", event.OccurredOn()),
+		)
+		return nil
+	}
+	data, ok := definition.Start(event)
+	if !ok {
+		return nil
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga data: %w", err)
+	}
+
+	row := po.SagaInstancePO{
+		ID:            uuid.NewString(),
+		SagaType:      definition.Type,
+		CorrelationID: event.GetAggregateID(),
+		Status:        SagaStatusRunning,
+		Data:          string(payload),
+		NextRunAt:     time.Now(),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return fmt.Errorf("failed to start saga %s: %w", definition.Type, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Saga started",
+			zap.String("saga_id", row.ID),
+			zap.String("saga_type", definition.Type),
+			zap.String("correlation_id", row.CorrelationID),
+		)
+	}
+	return nil
+}
+
+// abort 只记录中止原因，由持有租约的执行者在保存步骤结果时转入补偿；
+// 未被认领的实例同时提前到期，以便尽快补偿。
+func (m *SagaManager) abort(db *gorm.DB, definition shared.SagaDefinition, event shared.DomainEvent) error {
+	result := db.Model(&po.SagaInstancePO{}).
+		Where("saga_type = ? AND correlation_id = ? AND status = ? AND abort_reason = ''",
+			definition.Type, event.GetAggregateID(), SagaStatusRunning).
+		Updates(map[string]any{
+			"abort_reason": "aborted by " + event.EventName(),
+			"next_run_at":  gorm.Expr("CASE WHEN lease_owner = '' THEN ? ELSE next_run_at END", time.Now()),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to abort saga %s: %w", definition.Type, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Saga abort requested",
+			zap.String("saga_type", definition.Type),
+			zap.String("correlation_id", event.GetAggregateID()),
+			zap.String("event_type", event.EventName()),
+		)
+	}
+	return nil
+}
+
+// Run 按 PollInterval 认领并执行到期的实例，直到 ctx 取消。
+func (m *SagaManager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		instances, err := m.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to claim saga instances", zap.Error(err))
+		}
+
+		var wg sync.WaitGroup
+		for i := range instances {
+			wg.Add(1)
+			go func(instance *po.SagaInstancePO) {
+				defer wg.Done()
+				if err := m.execute(ctx, instance); err != nil && ctx.Err() == nil {
+					logger.Error("Saga step could not be recorded, it will run again after the lease expires",
+						zap.String("saga_id", instance.ID),
+						zap.String("saga_type", instance.SagaType),
+						zap.Error(err),
+					)
+				}
+			}(&instances[i])
+		}
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *SagaManager) claim(ctx context.Context) ([]po.SagaInstancePO, error) {
+	definitions := m.registered()
+	if len(definitions) == 0 {
+		return nil, nil
+	}
+	types := make([]string, len(definitions))
+	for i, definition := range definitions {
+		types[i] = definition.Type
+	}
+
+	var claimed []po.SagaInstancePO
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var rows []po.SagaInstancePO
+		err := tx.Clauses(clause.Locking{
+			Strength: clause.LockingStrengthUpdate,
+			Options:  clause.LockingOptionsSkipLocked,
+		}).
+			Where("status IN ? AND saga_type IN ? AND next_run_at <= ?",
+				[]string{SagaStatusRunning, SagaStatusCompensating}, types, now).
+			Order("next_run_at ASC").
+			Limit(m.config.BatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to load due sagas: %w", err)
+		}
+
+		for i := range rows {
+			definition, _ := m.definition(rows[i].SagaType)
+			rows[i].LeaseOwner = m.config.Owner
+			rows[i].NextRunAt = now.Add(m.leaseFor(definition, &rows[i]))
+			err := tx.Model(&po.SagaInstancePO{}).
+				Where("id = ?", rows[i].ID).
+				Updates(map[string]any{
+					"lease_owner": rows[i].LeaseOwner,
+					"next_run_at": rows[i].NextRunAt,
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to lease saga %s: %w", rows[i].ID, err)
+			}
+		}
+		claimed = rows
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (m *SagaManager) leaseFor(definition shared.SagaDefinition, row *po.SagaInstancePO) time.Duration {
+	timeout := DefaultSagaStepTimeout
+	if row.Step >= 0 && row.Step < len(definition.Steps) {
+		timeout = stepTimeout(definition.Steps[row.Step])
+	}
+	return timeout + m.config.LeaseGrace
+}
+
+func stepTimeout(step shared.SagaStep) time.Duration {
+	if step.Timeout > 0 {
+		return step.Timeout
+	}
+	return DefaultSagaStepTimeout
+}
+
+// sagaTransition 是执行一个步骤之后实例的新状态。
+type sagaTransition struct {
+	Status      string
+	Step        int
+	Attempts    int
+	AbortReason string
+	LastError   string
+	Delay       time.Duration
+}
+
+func (m *SagaManager) execute(ctx context.Context, row *po.SagaInstancePO) error {
+	definition, ok := m.definition(row.SagaType)
+	if !ok {
+		return fmt.Errorf("saga %s is not registered", row.SagaType)
+	}
+	data := map[string]string{}
+	if err := json.Unmarshal([]byte(row.Data), &data); err != nil {
+		return fmt.Errorf("failed to decode saga data: %w", err)
+	}
+	state := &shared.SagaState{
+		ID:            row.ID,
+		Type:          row.SagaType,
+		CorrelationID: row.CorrelationID,
+		Data:          data,
+		Reason:        row.AbortReason,
+	}
+
+	if row.Status == SagaStatusRunning && row.AbortReason != "" {
+		return m.save(ctx, row, state, m.startCompensation(row, row.Step-1, row.AbortReason))
+	}
+	if row.Status == SagaStatusCompensating && row.Step < 0 {
+		return m.save(ctx, row, state, sagaTransition{Status: SagaStatusCompensated, Step: -1, AbortReason: row.AbortReason})
+	}
+	if row.Step < 0 || row.Step >= len(definition.Steps) {
+		return fmt.Errorf("saga %s is at step %d of %d", row.ID, row.Step, len(definition.Steps))
+	}
+
+	step := definition.Steps[row.Step]
+	var err error
+	if row.Status == SagaStatusRunning {
+		err = runSagaStep(ctx, stepTimeout(step), func(ctx context.Context) error { return step.Action(ctx, state) })
+	} else if step.Compensate != nil {
+		err = runSagaStep(ctx, stepTimeout(step), func(ctx context.Context) error { return step.Compensate(ctx, state) })
+	}
+	if ctx.Err() != nil {
+		// 进程正在退出，不记录本次结果，租约过期后由其他副本重新执行该步骤。
+		return ctx.Err()
+	}
+
+	var next sagaTransition
+	if row.Status == SagaStatusRunning {
+		next = m.afterAction(definition, row, err)
+	} else {
+		next = m.afterCompensation(row, err)
+	}
+	if err != nil {
+		logger.Warn("Saga step failed",
+			zap.String("saga_id", row.ID),
+			zap.String("saga_type", row.SagaType),
+			zap.String("step", step.Name),
+			zap.String("status", row.Status),
+			zap.Int("attempt", row.Attempts+1),
+			zap.Error(err),
+		)
+	}
+	return m.save(ctx, row, state, next)
+}
+
+// afterAction 计算推进步骤之后的状态。
+func (m *SagaManager) afterAction(definition shared.SagaDefinition, row *po.SagaInstancePO, err error) sagaTransition {
+	step := definition.Steps[row.Step]
+	if err == nil {
+		if row.Step+1 >= len(definition.Steps) {
+			return sagaTransition{Status: SagaStatusCompleted, Step: len(definition.Steps)}
+		}
+		return sagaTransition{Status: SagaStatusRunning, Step: row.Step + 1}
+	}
+
+	attempts := row.Attempts + 1
+	maxAttempts := step.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	if errors.Is(err, shared.ErrSagaStepRejected) || attempts >= maxAttempts {
+		next := m.startCompensation(row, row.Step-1, fmt.Sprintf("%s failed: %v", step.Name, err))
+		next.LastError = err.Error()
+		return next
+	}
+	return sagaTransition{
+		Status:    SagaStatusRunning,
+		Step:      row.Step,
+		Attempts:  attempts,
+		LastError: err.Error(),
+		Delay:     retry.ExponentialBackoffWithJitter(attempts, m.config.RetryBackoff),
+	}
+}
+
+// afterCompensation 计算补偿步骤之后的状态。
+func (m *SagaManager) afterCompensation(row *po.SagaInstancePO, err error) sagaTransition {
+	if err == nil {
+		if row.Step-1 < 0 {
+			return sagaTransition{Status: SagaStatusCompensated, Step: -1, AbortReason: row.AbortReason}
+		}
+		return sagaTransition{Status: SagaStatusCompensating, Step: row.Step - 1, AbortReason: row.AbortReason}
+	}
+
+	attempts := row.Attempts + 1
+	if attempts >= m.config.CompensationMaxAttempts {
+		return sagaTransition{
+			Status:      SagaStatusFailed,
+			Step:        row.Step,
+			Attempts:    attempts,
+			AbortReason: row.AbortReason,
+			LastError:   err.Error(),
+		}
+	}
+	return sagaTransition{
+		Status:      SagaStatusCompensating,
+		Step:        row.Step,
+		Attempts:    attempts,
+		AbortReason: row.AbortReason,
+		LastError:   err.Error(),
+		Delay:       retry.ExponentialBackoffWithJitter(attempts, m.config.RetryBackoff),
+	}
+}
+
+// startCompensation 从 lastCompleted 开始逆序补偿，没有已完成的步骤时直接结束。
+func (m *SagaManager) startCompensation(row *po.SagaInstancePO, lastCompleted int, reason string) sagaTransition {
+	if row.AbortReason != "" {
+		reason = row.AbortReason
+	}
+	reason = truncateUTF8(reason, 255)
+	if lastCompleted < 0 {
+		return sagaTransition{Status: SagaStatusCompensated, Step: -1, AbortReason: reason}
+	}
+	return sagaTransition{Status: SagaStatusCompensating, Step: lastCompleted, AbortReason: reason}
+}
+
+// save 校验租约后写入步骤结果。推进期间到达的中止请求在这里生效：刚完成的步骤也会被补偿。
+func (m *SagaManager) save(ctx context.Context, row *po.SagaInstancePO, state *shared.SagaState, next sagaTransition) error {
+	payload, err := json.Marshal(state.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga data: %w", err)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current po.SagaInstancePO
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("id = ?", row.ID).
+			First(&current).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock saga %s: %w", row.ID, err)
+		}
+		if current.LeaseOwner != m.config.Owner || current.Status != row.Status || current.Step != row.Step {
+			logger.Warn("Saga lease lost before the step result was saved, discarding result",
+				zap.String("saga_id", row.ID),
+				zap.String("lease_owner", current.LeaseOwner),
+			)
+			return nil
+		}
+
+		if current.AbortReason != "" && (next.Status == SagaStatusRunning || next.Status == SagaStatusCompleted) {
+			lastError := next.LastError
+			next = m.startCompensation(&current, next.Step-1, current.AbortReason)
+			next.LastError = lastError
+		}
+
+		err = tx.Model(&po.SagaInstancePO{}).
+			Where("id = ?", row.ID).
+			Updates(map[string]any{
+				"status":       next.Status,
+				"step":         next.Step,
+				"attempts":     next.Attempts,
+				"data":         string(payload),
+				"abort_reason": next.AbortReason,
+				"last_error":   next.LastError,
+				"lease_owner":  "",
+				"next_run_at":  time.Now().Add(next.Delay),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to save saga %s: %w", row.ID, err)
+		}
+
+		if next.Status != row.Status {
+			logSagaTransition(row, next)
+		}
+		return nil
+	})
+}
+
+func logSagaTransition(row *po.SagaInstancePO, next sagaTransition) {
+	fields := []zap.Field{
+		zap.String("saga_id", row.ID),
+		zap.String("saga_type", row.SagaType),
+		zap.String("correlation_id", row.CorrelationID),
+		zap.String("status", next.Status),
+	}
+	switch next.Status {
+	case SagaStatusFailed:
+		logger.Error("Saga compensation exhausted its attempts, manual intervention required",
+			append(fields, zap.String("last_error", next.LastError))...)
+	case SagaStatusCompensating, SagaStatusCompensated:
+		logger.Warn("Saga compensating", append(fields, zap.String("reason", next.AbortReason))...)
+	default:
+		logger.Info("Saga status changed", fields...)
+	}
+}
+
+// runSagaStep 以超时执行步骤。超时后不再等待步骤返回，步骤须自行响应 ctx 取消。
+func runSagaStep(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("saga step panicked: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("saga step timed out after %s: %w", timeout, ctx.Err())
+	}
+}
+
+// ListSagas 按条件列出实例，按更新时间倒序。
+func (m *SagaManager) ListSagas(ctx context.Context, filter SagaFilter) ([]SagaInstance, error) {
+	query := m.db.WithContext(ctx).Model(&po.SagaInstancePO{})
+	if filter.Type != "" {
+		query = query.Where("saga_type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var rows []po.SagaInstancePO
+	if err := query.Order("updated_at DESC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+	instances := make([]SagaInstance, len(rows))
+	for i, row := range rows {
+		instances[i] = SagaInstance{
+			ID:            row.ID,
+			Type:          row.SagaType,
+			CorrelationID: row.CorrelationID,
+			Status:        row.Status,
+			Step:          m.stepName(row.SagaType, row.Step),
+			Attempts:      row.Attempts,
+			AbortReason:   row.AbortReason,
+			LastError:     row.LastError,
+			NextRunAt:     row.NextRunAt,
+			UpdatedAt:     row.UpdatedAt,
+		}
+	}
+	return instances, nil
+}
+
+func (m *SagaManager) stepName(sagaType string, step int) string {
+	definition, ok := m.definition(sagaType)
+	if !ok || step < 0 || step >= len(definition.Steps) {
+		return "-"
+	}
+	return definition.Steps[step].Name
+}
+
+// RetrySaga 让 FAILED 的实例从失败的补偿步骤重新开始补偿。
+func (m *SagaManager) RetrySaga(ctx context.Context, id string) error {
+	result := m.db.WithContext(ctx).Model(&po.SagaInstancePO{}).
+		Where("id = ? AND status = ?", id, SagaStatusFailed).
+		Updates(map[string]any{
+			"status":      SagaStatusCompensating,
+			"attempts":    0,
+			"lease_owner": "",
+			"next_run_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to retry saga %s: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saga %s not found or not FAILED", id)
+	}
+	return nil
+}
+
+var _ Projection = (*SagaManager)(nil)
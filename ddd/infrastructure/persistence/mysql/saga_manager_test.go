@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence/mysql/po"
+)
+
+func TestSagaManagerTransitions(t *testing.T) {
+	noop := func(context.Context, *shared.SagaState) error { return nil }
+	definition := shared.SagaDefinition{
+		Type: "test",
+		Steps: []shared.SagaStep{
+			{Name: "first", Action: noop, MaxAttempts: 3},
+			{Name: "second", Action: noop, MaxAttempts: 3},
+		},
+	}
+	manager := NewSagaManager(nil, SagaManagerConfig{CompensationMaxAttempts: 2})
+	transient := errors.New("timeout")
+
+	cases := []struct {
+		name       string
+		row        po.SagaInstancePO
+		err        error
+		compensate bool
+		wantStatus string
+		wantStep   int
+	}{
+		{"advance", po.SagaInstancePO{Status: SagaStatusRunning, Step: 0}, nil, false, SagaStatusRunning, 1},
+		{"complete", po.SagaInstancePO{Status: SagaStatusRunning, Step: 1}, nil, false, SagaStatusCompleted, 2},
+		{"retry", po.SagaInstancePO{Status: SagaStatusRunning, Step: 1, Attempts: 1}, transient, false, SagaStatusRunning, 1},
+		{"attempts exhausted", po.SagaInstancePO{Status: SagaStatusRunning, Step: 1, Attempts: 2}, transient, false, SagaStatusCompensating, 0},
+		{"rejected", po.SagaInstancePO{Status: SagaStatusRunning, Step: 1}, shared.RejectSagaStep(transient), false, SagaStatusCompensating, 0},
+		{"first step rejected", po.SagaInstancePO{Status: SagaStatusRunning, Step: 0}, shared.RejectSagaStep(transient), false, SagaStatusCompensated, -1},
+		{"compensate", po.SagaInstancePO{Status: SagaStatusCompensating, Step: 1}, nil, true, SagaStatusCompensating, 0},
+		{"compensated", po.SagaInstancePO{Status: SagaStatusCompensating, Step: 0}, nil, true, SagaStatusCompensated, -1},
+		{"compensation retry", po.SagaInstancePO{Status: SagaStatusCompensating, Step: 0}, transient, true, SagaStatusCompensating, 0},
+		{"compensation failed", po.SagaInstancePO{Status: SagaStatusCompensating, Step: 0, Attempts: 1}, transient, true, SagaStatusFailed, 0},
+	}
+	for _, c := range cases {
+		var next sagaTransition
+		if c.compensate {
+			next = manager.afterCompensation(&c.row, c.err)
+		} else {
+			next = manager.afterAction(definition, &c.row, c.err)
+		}
+		if next.Status != c.wantStatus || next.Step != c.wantStep {
+			t.Errorf("%s: got %s at step %d, want %s at step %d", c.name, next.Status, next.Step, c.wantStatus, c.wantStep)
+		}
+		if next.Status == SagaStatusCompensating && !c.compensate && !strings.HasPrefix(next.AbortReason, "second failed") {
+			t.Errorf("%s: abort reason = %q, want the failed step", c.name, next.AbortReason)
+		}
+	}
+}
+
+func TestSagaAbortReasonKeepsRuneBoundary(t *testing.T) {
+	manager := NewSagaManager(nil, SagaManagerConfig{})
+	reason := "x" + strings.Repeat("库存不足", 30)
+	next := manager.startCompensation(&po.SagaInstancePO{Status: SagaStatusRunning, Step: 1}, 0, reason)
+	if len(next.AbortReason) > 255 || !utf8.ValidString(next.AbortReason) {
+		t.Errorf("abort reason = %d bytes, valid UTF-8 %t, want at most 255 valid bytes",
+			len(next.AbortReason), utf8.ValidString(next.AbortReason))
+	}
+}
+
+func TestRunSagaStepTimesOut(t *testing.T) {
+	err := runSagaStep(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runSagaStep() error = %v, want deadline exceeded", err)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"ddd/domain/shared"
 	"ddd/infrastructure/persistence"
 	"ddd/infrastructure/persistence/retry"
+	"ddd/pkg/logger"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -20,6 +22,7 @@ type UnitOfWork struct {
 	aggregates       []shared.AggregateRoot
 	outboxRepository *OutboxRepository
 	retryConfig      retry.Config
+	eventPublisher   shared.DomainEventPublisher
 }
 
 func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
@@ -35,7 +38,12 @@ func (u *UnitOfWork) SetRetryConfig(config retry.Config) {
 	u.retryConfig = config
 }
 
-// Execute 执行事务函数，并在提交前收集聚合事件写入 outbox。
+// SetEventPublisher 设置提交后用于进程内分发事件的发布器，为空时只写 outbox。
+func (u *UnitOfWork) SetEventPublisher(publisher shared.DomainEventPublisher) {
+	u.eventPublisher = publisher
+}
+
+// Execute 执行事务函数，并在提交前收集聚合事件写入 outbox；提交成功后再将同一批事件交给进程内发布器。
 func (u *UnitOfWork) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -43,8 +51,10 @@ func (u *UnitOfWork) Execute(ctx context.Context, fn func(ctx context.Context) e
 		defer cancel()
 	}
 
+	var committed []shared.DomainEvent
 	executeOnce := func(ctx context.Context) error {
 		u.aggregates = make([]shared.AggregateRoot, 0)
+		committed = nil
 
 		tx := u.db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelReadCommitted})
 		if tx.Error != nil {
@@ -57,23 +67,52 @@ func (u *UnitOfWork) Execute(ctx context.Context, fn func(ctx context.Context) e
 			return err
 		}
 
+		var events []shared.DomainEvent
 		for _, agg := range u.aggregates {
-			events := agg.PullEvents()
-			for _, event := range events {
-				if err := u.outboxRepository.SaveEvent(txCtx, event); err != nil {
-					tx.Rollback()
-					return fmt.Errorf("failed to save event to outbox: %w", err)
-				}
+			pulled := agg.PullEvents()
+			// SaveEvents 为同一聚合的事件分配连续的 aggregate_sequence，供 worker 按聚合有序投递。
+			if err := u.outboxRepository.SaveEvents(txCtx, pulled); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to save event to outbox: %w", err)
 			}
+			events = append(events, pulled...)
 		}
 
 		if err := tx.Commit().Error; err != nil {
 			return fmt.Errorf("failed to commit transaction: %w", err)
 		}
+		committed = events
 		return nil
 	}
 
-	return retry.ExecuteWithRetry(ctx, u.retryConfig, executeOnce)
+	if err := retry.ExecuteWithRetry(ctx, u.retryConfig, executeOnce); err != nil {
+		return err
+	}
+	u.dispatchCommitted(ctx, committed)
+	return nil
+}
+
+// dispatchCommitted 在事务提交后分发事件。事务已不可回滚，处理失败只记录日志，
+// 可靠的跨进程投递仍由 outbox 保证。发布器支持 ctx 时传入调用方的 ctx，处理器可取得请求 ID。
+func (u *UnitOfWork) dispatchCommitted(ctx context.Context, events []shared.DomainEvent) {
+	if u.eventPublisher == nil {
+		return
+	}
+	publish := u.eventPublisher.Publish
+	if publisher, ok := u.eventPublisher.(shared.ContextEventPublisher); ok {
+		publish = func(event shared.DomainEvent) error {
+			return publisher.PublishContext(ctx, event)
+		}
+	}
+	for _, event := range events {
+		if err := publish(event); err != nil {
+			logger.Warn("In-process event dispatch failed after commit",
+				zap.String("event_type", event.EventName()),
+				zap.String("aggregate_id", event.GetAggregateID()),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 func (u *UnitOfWork) RegisterNew(aggregate shared.AggregateRoot) {
@@ -8,8 +8,9 @@ import (
 )
 
 type UnitOfWorkFactory struct {
-	db          *gorm.DB
-	retryConfig retry.Config
+	db             *gorm.DB
+	retryConfig    retry.Config
+	eventPublisher shared.DomainEventPublisher
 }
 
 func NewUnitOfWorkFactory(db *gorm.DB, retryConfig retry.Config) *UnitOfWorkFactory {
@@ -18,9 +19,16 @@ func NewUnitOfWorkFactory(db *gorm.DB, retryConfig retry.Config) *UnitOfWorkFact
 		retryConfig: retryConfig,
 	}
 }
+
+// SetEventPublisher 使创建的 UnitOfWork 在提交后将事件分发给 publisher。
+func (f *UnitOfWorkFactory) SetEventPublisher(publisher shared.DomainEventPublisher) {
+	f.eventPublisher = publisher
+}
+
 func (f *UnitOfWorkFactory) New() shared.UnitOfWork {
 	uow := NewUnitOfWork(f.db)
 	uow.SetRetryConfig(f.retryConfig)
+	uow.SetEventPublisher(f.eventPublisher)
 	return uow
 }
 
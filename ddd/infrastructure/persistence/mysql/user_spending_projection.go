@@ -0,0 +1,217 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ddd/domain/order"
+	"ddd/domain/shared"
+	"ddd/infrastructure/persistence"
+	"ddd/infrastructure/persistence/mysql/po"
+	"ddd/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSpendingProjectionName 是用户消费汇总投影的检查点名称。
+const UserSpendingProjectionName = "user_spending_summary"
+
+// UserSpendingProjection 维护每个用户的下单数、签收数与已签收订单总额，
+// 同时实现 order.UserSpendingReader，替代按用户加载全部订单再求和。
+//
+// 签收事件不携带金额，投影在 user_spending_orders 中记录每个订单的归属与金额，
+// 在 user_spending_order_items 中记录订单项小计以便移除时扣减。引入订单项事件之后的订单
+// 以订单项事件累计金额；之前的订单没有订单项事件，以下单金额为准。
+// 事件源中缺少下单事件的订单（例如已被保留任务删除）无法计入，相关事件记录日志后跳过。
+type UserSpendingProjection struct {
+	db *gorm.DB
+}
+
+func NewUserSpendingProjection(db *gorm.DB) *UserSpendingProjection {
+	return &UserSpendingProjection{db: db}
+}
+
+func (p *UserSpendingProjection) Name() string { return UserSpendingProjectionName }
+
+func (p *UserSpendingProjection) getDB(ctx context.Context) *gorm.DB {
+	if tx := persistence.TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return p.db.WithContext(ctx)
+}
+
+func (p *UserSpendingProjection) Apply(ctx context.Context, event shared.DomainEvent) error {
+	db := p.getDB(ctx)
+	switch e := event.(type) {
+	case *order.OrderPlacedEvent:
+		return p.applyPlaced(db, e)
+	case *order.OrderItemAddedEvent:
+		return p.applyItemAdded(db, e)
+	case *order.OrderItemRemovedEvent:
+		return p.applyItemRemoved(db, e)
+	case *order.OrderDeliveredEvent:
+		return p.applyDelivered(db, e)
+	case *order.OrderConfirmedEvent:
+		return p.setStatus(db, e.OrderID(), order.StatusConfirmed)
+	case *order.OrderShippedEvent:
+		return p.setStatus(db, e.OrderID(), order.StatusShipped)
+	case *order.OrderCancelledEvent:
+		return p.setStatus(db, e.OrderID(), order.StatusCancelled)
+	default:
+		return nil
+	}
+}
+
+func (p *UserSpendingProjection) applyPlaced(db *gorm.DB, e *order.OrderPlacedEvent) error {
+	currency := e.TotalAmount().Currency()
+	err := db.Create(&po.UserSpendingOrderPO{
+		OrderID:     e.OrderID(),
+		UserID:      e.UserID(),
+		Currency:    currency,
+		PlacedTotal: e.TotalAmount().Amount(),
+		Status:      string(order.StatusPending),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record order %s: %w", e.OrderID(), err)
+	}
+	return p.upsertSummary(db, e.UserID(), currency, map[string]any{
+		"order_count": gorm.Expr("order_count + 1"),
+	})
+}
+
+func (p *UserSpendingProjection) applyItemAdded(db *gorm.DB, e *order.OrderItemAddedEvent) error {
+	item := e.Item()
+	err := db.Create(&po.UserSpendingOrderItemPO{
+		ItemID:   item.ID(),
+		OrderID:  e.OrderID(),
+		Subtotal: item.Subtotal().Amount(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record item %s: %w", item.ID(), err)
+	}
+	return p.adjustItems(db, e.OrderID(), item.Subtotal().Amount())
+}
+
+// applyItemRemoved 移除事件只携带订单项 ID，金额从添加时记录的订单项中找回。
+func (p *UserSpendingProjection) applyItemRemoved(db *gorm.DB, e *order.OrderItemRemovedEvent) error {
+	var item po.UserSpendingOrderItemPO
+	err := db.Where("item_id = ?", e.ItemID()).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		skipUntrackedOrder(e)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find removed item %s: %w", e.ItemID(), err)
+	}
+	if err := db.Delete(&item).Error; err != nil {
+		return err
+	}
+	return p.adjustItems(db, e.OrderID(), -item.Subtotal)
+}
+
+func (p *UserSpendingProjection) adjustItems(db *gorm.DB, orderID string, delta int64) error {
+	result := db.Model(&po.UserSpendingOrderPO{}).
+		Where("order_id = ?", orderID).
+		Updates(map[string]any{
+			"items_total": gorm.Expr("items_total + ?", delta),
+			"item_events": gorm.Expr("item_events + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		logger.Warn("User spending projection has no order.placed for order, skipping item change",
+			zap.String("order_id", orderID),
+		)
+	}
+	return nil
+}
+
+func (p *UserSpendingProjection) setStatus(db *gorm.DB, orderID string, status order.Status) error {
+	return db.Model(&po.UserSpendingOrderPO{}).
+		Where("order_id = ?", orderID).
+		Update("status", string(status)).Error
+}
+
+func (p *UserSpendingProjection) applyDelivered(db *gorm.DB, e *order.OrderDeliveredEvent) error {
+	orderID := e.OrderID()
+	var tracked po.UserSpendingOrderPO
+	err := db.Where("order_id = ?", orderID).First(&tracked).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		skipUntrackedOrder(e)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load order %s for delivery: %w", orderID, err)
+	}
+	if tracked.Status == string(order.StatusDelivered) {
+		return nil
+	}
+
+	total := tracked.PlacedTotal
+	if tracked.ItemEvents > 0 {
+		total = tracked.ItemsTotal
+	}
+	if err := p.setStatus(db, orderID, order.StatusDelivered); err != nil {
+		return err
+	}
+	return p.upsertSummary(db, tracked.UserID, tracked.Currency, map[string]any{
+		"delivered_orders": gorm.Expr("delivered_orders + 1"),
+		"total_spent":      gorm.Expr("total_spent + ?", total),
+	})
+}
+
+func skipUntrackedOrder(event shared.DomainEvent) {
+	logger.Warn("User spending projection has no order.placed for order, skipping event",
+		zap.String("event_type", event.EventName()),
+		zap.String("order_id", event.GetAggregateID()),
+	)
+}
+
+func (p *UserSpendingProjection) upsertSummary(db *gorm.DB, userID, currency string, updates map[string]any) error {
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&po.UserSpendingSummaryPO{UserID: userID, Currency: currency}).Error
+	if err != nil {
+		return fmt.Errorf("failed to create spending summary: %w", err)
+	}
+	return db.Model(&po.UserSpendingSummaryPO{}).
+		Where("user_id = ? AND currency = ?", userID, currency).
+		Updates(updates).Error
+}
+
+func (p *UserSpendingProjection) Reset(ctx context.Context) error {
+	db := p.getDB(ctx)
+	for _, model := range []any{&po.UserSpendingSummaryPO{}, &po.UserSpendingOrderPO{}, &po.UserSpendingOrderItemPO{}} {
+		if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to reset user spending projection: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *UserSpendingProjection) FindUserSpending(ctx context.Context, userID string) ([]*order.UserSpending, error) {
+	var rows []po.UserSpendingSummaryPO
+	if err := p.getDB(ctx).Where("user_id = ?", userID).Order("currency ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user spending: %w", err)
+	}
+	result := make([]*order.UserSpending, len(rows))
+	for i, row := range rows {
+		result[i] = &order.UserSpending{
+			UserID:          row.UserID,
+			Currency:        row.Currency,
+			OrderCount:      row.OrderCount,
+			DeliveredOrders: row.DeliveredOrders,
+			TotalSpent:      row.TotalSpent,
+			UpdatedAt:       row.UpdatedAt,
+		}
+	}
+	return result, nil
+}
+
+var (
+	_ Projection               = (*UserSpendingProjection)(nil)
+	_ order.UserSpendingReader = (*UserSpendingProjection)(nil)
+)
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ddd/domain/order"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserSpendingProjectionDeliveredTotal(t *testing.T) {
+	cases := []struct {
+		name       string
+		itemEvents int
+		want       int64
+	}{
+		{"items total when item events were seen", 2, 1500},
+		{"placed total for orders without item events", 0, 1000},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			projection := NewUserSpendingProjection(db)
+
+			mock.ExpectQuery("SELECT \\* FROM `user_spending_orders` WHERE order_id = \\?").
+				WithArgs("order-1", 1).
+				WillReturnRows(sqlmock.NewRows([]string{"order_id", "user_id", "currency", "placed_total", "items_total", "item_events", "status"}).
+					AddRow("order-1", "user-1", "CNY", 1000, 1500, c.itemEvents, string(order.StatusShipped)))
+			mock.ExpectExec("UPDATE `user_spending_orders` SET `status`=\\?").
+				WithArgs(string(order.StatusDelivered), "order-1").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("INSERT INTO `user_spending_summary`").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("UPDATE `user_spending_summary` SET .*`total_spent`=total_spent \\+ \\?").
+				WithArgs(c.want, sqlmock.AnyArg(), "user-1", "CNY").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			event := order.RebuildOrderDeliveredEvent("order-1", time.Now())
+			if err := projection.Apply(context.Background(), event); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUserSpendingProjectionItemRemovedSubtractsSubtotal(t *testing.T) {
+	db, mock := newMockDB(t)
+	projection := NewUserSpendingProjection(db)
+
+	mock.ExpectQuery("SELECT \\* FROM `user_spending_order_items` WHERE item_id = \\?").
+		WithArgs("item-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "subtotal"}).
+			AddRow("item-1", "order-1", 300))
+	mock.ExpectExec("DELETE FROM `user_spending_order_items`").
+		WithArgs("item-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `user_spending_orders` SET `item_events`=item_events \\+ 1,`items_total`=items_total \\+ \\?").
+		WithArgs(int64(-300), "order-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	event := order.RebuildOrderItemRemovedEvent("order-1", "item-1", time.Now())
+	if err := projection.Apply(context.Background(), event); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+}
@@ -43,6 +43,161 @@ CREATE TABLE IF NOT EXISTS order_items (
     INDEX idx_order_items_order_id (order_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- Order history table (full order state after every save, for as-of queries)
+CREATE TABLE IF NOT EXISTS order_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id VARCHAR(64) NOT NULL,
+    version INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    schema_version INT NOT NULL,
+    payload JSON NOT NULL,
+    recorded_at DATETIME(3) NOT NULL,
+    INDEX idx_order_history_order_recorded (order_id, recorded_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id VARCHAR(64) PRIMARY KEY,
+    aggregate_id VARCHAR(64) NOT NULL,
+    aggregate_sequence BIGINT NOT NULL DEFAULT 0,
+    event_type VARCHAR(100) NOT NULL,
+    event_version INT NOT NULL DEFAULT 1,
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    retry_count INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NULL,
+    last_error TEXT NULL,
+    lease_owner VARCHAR(128) NULL,
+    lease_expires_at DATETIME(3) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_outbox_events_aggregate_id (aggregate_id),
+    UNIQUE INDEX uk_outbox_events_aggregate_sequence (aggregate_id, aggregate_sequence),
+    INDEX idx_outbox_events_event_type (event_type),
+    INDEX idx_outbox_events_status_created_at (status, created_at),
+    INDEX idx_outbox_events_next_attempt_at (next_attempt_at),
+    INDEX idx_outbox_events_lease_expires_at (lease_expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS outbox_events_archive (
+    id VARCHAR(64) PRIMARY KEY,
+    aggregate_id VARCHAR(64) NOT NULL,
+    aggregate_sequence BIGINT NOT NULL DEFAULT 0,
+    event_type VARCHAR(100) NOT NULL,
+    event_version INT NOT NULL DEFAULT 1,
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    retry_count INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    created_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NULL,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_outbox_events_archive_aggregate_id (aggregate_id),
+    INDEX idx_outbox_events_archive_event_type (event_type),
+    INDEX idx_outbox_events_archive_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS outbox_event_audits (
+    id VARCHAR(64) PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    operator VARCHAR(100) NOT NULL,
+    note TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_outbox_event_audits_event_id (event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id VARCHAR(64) NOT NULL,
+    consumer VARCHAR(100) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, consumer),
+    INDEX idx_processed_events_processed_at (processed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS event_store (
+    position BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    event_version INT NOT NULL DEFAULT 1,
+    payload JSON NOT NULL,
+    occurred_on DATETIME(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX uk_event_store_event_id (event_id),
+    UNIQUE INDEX uk_event_store_aggregate_version (aggregate_id, version),
+    INDEX idx_event_store_aggregate_type (aggregate_type),
+    INDEX idx_event_store_event_type (event_type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS aggregate_snapshots (
+    aggregate_id VARCHAR(64) PRIMARY KEY,
+    aggregate_type VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    schema_version INT NOT NULL,
+    payload JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_aggregate_snapshots_aggregate_type (aggregate_type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Projection checkpoints table (read model projections)
+CREATE TABLE IF NOT EXISTS projection_checkpoints (
+    name VARCHAR(100) PRIMARY KEY,
+    source VARCHAR(20) NOT NULL,
+    position VARCHAR(64) NOT NULL DEFAULT '',
+    processed BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- User spending summary read model
+CREATE TABLE IF NOT EXISTS user_spending_summary (
+    user_id VARCHAR(64) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    order_count BIGINT NOT NULL DEFAULT 0,
+    delivered_orders BIGINT NOT NULL DEFAULT 0,
+    total_spent BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS user_spending_orders (
+    order_id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    placed_total BIGINT NOT NULL,
+    items_total BIGINT NOT NULL DEFAULT 0,
+    item_events INT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS user_spending_order_items (
+    item_id VARCHAR(128) PRIMARY KEY,
+    order_id VARCHAR(64) NOT NULL,
+    subtotal BIGINT NOT NULL,
+    INDEX idx_user_spending_order_items_order_id (order_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Saga instances table (long-running workflows driven by domain events)
+CREATE TABLE IF NOT EXISTS saga_instances (
+    id VARCHAR(36) PRIMARY KEY,
+    saga_type VARCHAR(50) NOT NULL,
+    correlation_id VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    step INT NOT NULL DEFAULT 0,
+    attempts INT NOT NULL DEFAULT 0,
+    data JSON NOT NULL,
+    abort_reason VARCHAR(255) NOT NULL DEFAULT '',
+    last_error TEXT,
+    lease_owner VARCHAR(100) NOT NULL DEFAULT '',
+    next_run_at DATETIME(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_saga_instances_type_correlation (saga_type, correlation_id),
+    INDEX idx_saga_instances_status_next_run (status, next_run_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- Insert sample data for testing
 INSERT INTO users (id, name, email, age, is_active) VALUES
     ('user-sample-1', 'Zhang San', 'zhangsan@example.com', 25, TRUE),
@@ -0,0 +1,36 @@
+-- Upgrade an outbox_events table created by the baseline DDL
+-- (id, aggregate_id, event_type, payload, status, retry_count, created_at, updated_at)
+-- to the schema in init.sql. Requires MySQL 8.0+ (window functions).
+--
+-- Run once, with every API and worker process of the old version stopped:
+-- old processes do not write aggregate_sequence, so their inserts would
+-- collide on uk_outbox_events_aggregate_sequence after step 3.
+
+-- 1. Add the columns introduced by leasing, backoff, ordering and the codec registry.
+ALTER TABLE outbox_events
+    ADD COLUMN aggregate_sequence BIGINT NOT NULL DEFAULT 0 AFTER aggregate_id,
+    ADD COLUMN event_version INT NOT NULL DEFAULT 1 AFTER event_type,
+    ADD COLUMN next_attempt_at DATETIME(3) NULL AFTER retry_count,
+    ADD COLUMN last_error TEXT NULL AFTER next_attempt_at,
+    ADD COLUMN lease_owner VARCHAR(128) NULL AFTER last_error,
+    ADD COLUMN lease_expires_at DATETIME(3) NULL AFTER lease_owner,
+    ADD INDEX idx_outbox_events_status_created_at (status, created_at),
+    ADD INDEX idx_outbox_events_next_attempt_at (next_attempt_at),
+    ADD INDEX idx_outbox_events_lease_expires_at (lease_expires_at);
+
+-- 2. Number existing events per aggregate in creation order, starting at 1.
+--    New events continue from MAX(aggregate_sequence) + 1. updated_at is kept
+--    so the reaper still sees how long legacy PROCESSING rows have been stuck.
+UPDATE outbox_events e
+JOIN (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY aggregate_id ORDER BY created_at, id) AS seq
+    FROM outbox_events
+) numbered ON numbered.id = e.id
+SET e.aggregate_sequence = numbered.seq, e.updated_at = e.updated_at;
+
+-- 3. Enforce one event per (aggregate, sequence) now that the backfill is unique.
+ALTER TABLE outbox_events
+    ADD UNIQUE INDEX uk_outbox_events_aggregate_sequence (aggregate_id, aggregate_sequence);
+
+-- Legacy PROCESSING rows keep lease_expires_at NULL; the worker's reaper
+-- returns them to PENDING once updated_at is older than worker.lease_duration.